@@ -0,0 +1,157 @@
+package main
+
+import (
+	"flag"
+	"github.com/go-errors/errors"
+	"github.com/tyler-smith/go-bip39"
+	"github.com/velas/GoVelas/crypto"
+	"os"
+	"strings"
+)
+
+// Default derivation path of keys from mnemonic, it is the same as HDFromSeed uses
+const defaultPath = "m/0'"
+
+// Key pair and address in output of key commands
+type keyResult struct {
+	Mnemonic   string `json:"mnemonic,omitempty"`
+	Path       string `json:"path,omitempty"`
+	PrivateKey string `json:"private_key,omitempty"`
+	PublicKey  string `json:"public_key"`
+	Address    string `json:"address"`
+}
+
+// Flags for private key of commands, which sign transactions
+type keyFlags struct {
+	key      string
+	mnemonic string
+	path     string
+}
+
+// register add key flags to flag set
+func (kf *keyFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&kf.key, "key", "", "private key in hex, "+envPrivateKey+" is used if neither key nor mnemonic is set")
+	fs.StringVar(&kf.mnemonic, "mnemonic", "", "mnemonic phrase of key")
+	fs.StringVar(&kf.path, "path", defaultPath, "derivation path of key from mnemonic")
+}
+
+// load create key pair from flags or environment
+func (kf *keyFlags) load() (*crypto.HD, error) {
+	if kf.mnemonic != "" {
+		if kf.key != "" {
+			return nil, errors.Errorf("either key or mnemonic must be set, not both")
+		}
+		return hdFromMnemonic(kf.mnemonic, kf.path)
+	}
+	key := kf.key
+	if key == "" {
+		key = os.Getenv(envPrivateKey)
+	}
+	if key == "" {
+		return nil, errors.Errorf("private key is not set, use -key, -mnemonic or %s", envPrivateKey)
+	}
+	return crypto.HDFromPrivateKeyHex(strings.TrimSpace(key))
+}
+
+// hdFromMnemonic derive key pair from mnemonic phrase
+func hdFromMnemonic(mnemonic string, path string) (*crypto.HD, error) {
+	mnemonic = strings.Join(strings.Fields(mnemonic), " ")
+	if !bip39.IsMnemonicValid(mnemonic) {
+		return nil, errors.Errorf("invalid mnemonic")
+	}
+	return crypto.HDFromSeed(bip39.NewSeed(mnemonic, ""), &path)
+}
+
+// newKeyResult fill output of key commands
+func newKeyResult(hd *crypto.HD, withPrivate bool) (*keyResult, error) {
+	wallet, err := hd.ToWallet()
+	if err != nil {
+		return nil, err
+	}
+	result := &keyResult{PublicKey: hd.PublicKey(), Address: wallet.Base58Address}
+	if withPrivate {
+		result.PrivateKey = hd.PrivateKey()
+	}
+	return result, nil
+}
+
+// lines return text output of key
+func (kr *keyResult) lines() []string {
+	lines := make([]string, 0)
+	if kr.Mnemonic != "" {
+		lines = append(lines, field("mnemonic", kr.Mnemonic))
+	}
+	if kr.Path != "" {
+		lines = append(lines, field("path", kr.Path))
+	}
+	if kr.PrivateKey != "" {
+		lines = append(lines, field("private key", kr.PrivateKey))
+	}
+	return append(lines, field("public key", kr.PublicKey), field("address", kr.Address))
+}
+
+func runKeygen(e *env, args []string) error {
+	fs := e.flagSet("keygen")
+	withMnemonic := fs.Bool("mnemonic", false, "generate mnemonic phrase and derive key from it")
+	words := fs.Int("words", 12, "count of mnemonic words, 12 or 24")
+	path := fs.String("path", defaultPath, "derivation path of key from mnemonic")
+	if err := parseArgs(fs, args, 0, 0); err != nil {
+		return err
+	}
+
+	if !*withMnemonic {
+		hd, err := crypto.GenerateHD()
+		if err != nil {
+			return err
+		}
+		result, err := newKeyResult(hd, true)
+		if err != nil {
+			return err
+		}
+		return e.print(result, result.lines()...)
+	}
+
+	if *words != 12 && *words != 24 {
+		return errors.Errorf("count of mnemonic words must be 12 or 24")
+	}
+	entropy, err := bip39.NewEntropy(*words / 3 * 32)
+	if err != nil {
+		return errors.New(err)
+	}
+	mnemonic, err := bip39.NewMnemonic(entropy)
+	if err != nil {
+		return errors.New(err)
+	}
+	hd, err := hdFromMnemonic(mnemonic, *path)
+	if err != nil {
+		return err
+	}
+	result, err := newKeyResult(hd, true)
+	if err != nil {
+		return err
+	}
+	result.Mnemonic = mnemonic
+	result.Path = *path
+	return e.print(result, result.lines()...)
+}
+
+func runAddress(e *env, args []string) error {
+	fs := e.flagSet("address")
+	kf := keyFlags{}
+	kf.register(fs)
+	if err := parseArgs(fs, args, 0, 0); err != nil {
+		return err
+	}
+	hd, err := kf.load()
+	if err != nil {
+		return err
+	}
+	result, err := newKeyResult(hd, false)
+	if err != nil {
+		return err
+	}
+	if kf.mnemonic != "" {
+		result.Path = kf.path
+	}
+	return e.print(result, result.lines()...)
+}
@@ -0,0 +1,171 @@
+// Command velas is a command-line wallet tool for Velas nodes. It generates keys, derives addresses, requests balances,
+// unspents, blocks and node info, builds, signs, inspects, validates and publishes transactions.
+//
+// Usage:
+//
+//	velas [-node URL] [-json] <command> [flags] [args]
+//
+// Node address can be set with VELAS_NODE environment variable, private key with VELAS_PRIVATE_KEY, so it doesn't
+// appear in shell history. Run "velas help" for list of commands.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/go-errors/errors"
+	"github.com/velas/GoVelas/httpClient"
+	"io"
+	"os"
+	"sort"
+)
+
+// Environment variable with node address, it is used when -node flag is not set
+const envNode = "VELAS_NODE"
+
+// Environment variable with private key in hex, it is used when neither -key nor -mnemonic flag is set
+const envPrivateKey = "VELAS_PRIVATE_KEY"
+
+// Error of invalid command line, usage is printed for it
+var errUsage = errors.New("invalid usage")
+
+// Subcommand of tool
+type command struct {
+	usage string // arguments and description
+	run   func(e *env, args []string) error
+}
+
+// commands by name, they are set in init, because commands use the map for usage
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"keygen":   {usage: "[-mnemonic] [-words 12|24] [-path m/0'] - generate key pair", run: runKeygen},
+		"address":  {usage: "[-key HEX | -mnemonic WORDS [-path m/0']] - derive public key and address", run: runAddress},
+		"info":     {usage: "- show node info", run: runInfo},
+		"balance":  {usage: "ADDRESS - show balance summary", run: runBalance},
+		"unspent":  {usage: "[-staking] ADDRESS - list unspent outputs", run: runUnspent},
+		"build":    {usage: "-to ADDRESS -amount VLX [-commission VLX] [-locktime N] [-key HEX | -mnemonic WORDS] - build and sign transaction", run: runBuild},
+		"sign":     {usage: "[-in FILE] [-key HEX | -mnemonic WORDS] - sign transaction again, e.g. after editing", run: runSign},
+		"validate": {usage: "[-in FILE] - validate transaction with node", run: runValidate},
+		"inspect":  {usage: "[-in FILE] - decode transaction in json or GoVelas binary form, check its hash, signatures and amounts", run: runInspect},
+		"publish":  {usage: "[-in FILE] [-confirmations N] [-timeout DURATION] - publish transaction", run: runPublish},
+		"block":    {usage: "[HASH | -height N] - show block, the latest one by default", run: runBlock},
+	}
+}
+
+// State shared by commands
+type env struct {
+	node string    // address of node
+	json bool      // print results in json
+	in   io.Reader // transactions are read from it, if file is not set
+	out  io.Writer
+	err  io.Writer // usage and errors
+}
+
+// client return node client, node address is required
+func (e *env) client() (*httpClient.Client, error) {
+	if e.node == "" {
+		return nil, errors.Errorf("node address is not set, use -node flag or %s", envNode)
+	}
+	return httpClient.NewClient(e.node), nil
+}
+
+// print write value in json or text lines
+func (e *env) print(value interface{}, lines ...string) error {
+	if e.json {
+		data, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return errors.New(err)
+		}
+		lines = []string{string(data)}
+	}
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(e.out, line); err != nil {
+			return errors.New(err)
+		}
+	}
+	return nil
+}
+
+// flagSet create flag set of command, which doesn't exit on error
+func (e *env) flagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(e.err)
+	fs.Usage = func() {
+		fmt.Fprintf(e.err, "usage: velas %s %s\n", name, commands[name].usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+func main() {
+	e := &env{in: os.Stdin, out: os.Stdout, err: os.Stderr}
+	switch err := run(e, os.Args[1:]); {
+	case err == nil:
+	case err == flag.ErrHelp:
+	case err == errUsage:
+		os.Exit(2)
+	default:
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+// run parse global flags and run command
+func run(e *env, args []string) error {
+	fs := flag.NewFlagSet("velas", flag.ContinueOnError)
+	fs.SetOutput(e.err)
+	fs.StringVar(&e.node, "node", os.Getenv(envNode), "address of node, like http://127.0.0.1:5000")
+	fs.BoolVar(&e.json, "json", false, "print results in json")
+	fs.Usage = func() { usage(e.err, fs) }
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 || fs.Arg(0) == "help" {
+		fs.Usage()
+		if fs.NArg() == 0 {
+			return errUsage
+		}
+		return nil
+	}
+	cmd, ok := commands[fs.Arg(0)]
+	if !ok {
+		fmt.Fprintf(e.err, "unknown command %q\n", fs.Arg(0))
+		fs.Usage()
+		return errUsage
+	}
+	return cmd.run(e, fs.Args()[1:])
+}
+
+// usage print global flags and commands
+func usage(w io.Writer, fs *flag.FlagSet) {
+	fmt.Fprintln(w, "usage: velas [-node URL] [-json] <command> [flags] [args]")
+	fs.PrintDefaults()
+	fmt.Fprintln(w, "commands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-9s %s\n", name, commands[name].usage)
+	}
+}
+
+// parseArgs parse flags of command and check count of positional arguments
+func parseArgs(fs *flag.FlagSet, args []string, minArgs int, maxArgs int) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() < minArgs || fs.NArg() > maxArgs {
+		fs.Usage()
+		return errUsage
+	}
+	return nil
+}
+
+// field format line of text output
+func field(name string, value interface{}) string {
+	return fmt.Sprintf("%-16s %v", name+":", value)
+}
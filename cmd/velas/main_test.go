@@ -0,0 +1,203 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/velas/GoVelas/crypto"
+	"github.com/velas/GoVelas/httpClient"
+	"github.com/velas/GoVelas/httpClient/velastest"
+	"strings"
+	"testing"
+)
+
+// runTool run command with arguments and return its output
+func runTool(t *testing.T, in string, args ...string) (string, error) {
+	out := &bytes.Buffer{}
+	e := &env{in: strings.NewReader(in), out: out, err: &bytes.Buffer{}}
+	err := run(e, args)
+	return out.String(), err
+}
+
+// runJSON run command in json mode and decode its output
+func runJSON(t *testing.T, result interface{}, args ...string) {
+	out, err := runTool(t, "", append([]string{"-json"}, args...)...)
+	if err != nil {
+		t.Fatalf("%v error = %v", args, err)
+	}
+	if err := json.Unmarshal([]byte(out), result); err != nil {
+		t.Fatalf("%v output %s: %v", args, out, err)
+	}
+}
+
+func TestKeys(t *testing.T) {
+	random := keyResult{}
+	runJSON(t, &random, "keygen")
+	derived := keyResult{}
+	runJSON(t, &derived, "address", "-key", random.PrivateKey)
+	if derived.Address != random.Address || derived.PublicKey != random.PublicKey || derived.PrivateKey != "" {
+		t.Errorf("address of generated key got = %+v, want %+v", derived, random)
+	}
+
+	withMnemonic := keyResult{}
+	runJSON(t, &withMnemonic, "keygen", "-mnemonic", "-words", "24")
+	if len(strings.Fields(withMnemonic.Mnemonic)) != 24 || withMnemonic.Path != defaultPath {
+		t.Errorf("keygen with mnemonic got = %+v", withMnemonic)
+	}
+	derived = keyResult{}
+	runJSON(t, &derived, "address", "-mnemonic", withMnemonic.Mnemonic)
+	if derived.Address != withMnemonic.Address {
+		t.Errorf("address of mnemonic got = %s, want %s", derived.Address, withMnemonic.Address)
+	}
+	other := keyResult{}
+	runJSON(t, &other, "address", "-mnemonic", withMnemonic.Mnemonic, "-path", "m/0'/1'")
+	if other.Address == withMnemonic.Address || other.Path != "m/0'/1'" {
+		t.Errorf("address of another path got = %+v", other)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "Invalid words", args: []string{"keygen", "-mnemonic", "-words", "13"}},
+		{name: "Invalid mnemonic", args: []string{"address", "-mnemonic", "one two three"}},
+		{name: "Key and mnemonic", args: []string{"address", "-key", random.PrivateKey, "-mnemonic", withMnemonic.Mnemonic}},
+		{name: "Extra argument", args: []string{"keygen", "extra"}},
+		{name: "Unknown command", args: []string{"unknown"}},
+		{name: "No command", args: []string{}},
+		{name: "Node isn't set", args: []string{"-node", "", "info"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := runTool(t, "", tt.args...); err == nil {
+				t.Errorf("run(%v) must fail", tt.args)
+			}
+		})
+	}
+}
+
+func TestTransaction(t *testing.T) {
+	srv, err := velastest.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+	key := keyResult{}
+	runJSON(t, &key, "keygen")
+	receiver := keyResult{}
+	runJSON(t, &receiver, "keygen")
+	if _, err := srv.Fund(key.Address, 5*uint64(crypto.VLX)); err != nil {
+		t.Fatal(err)
+	}
+
+	balance := balanceResult{}
+	runJSON(t, &balance, "-node", srv.URL, "balance", key.Address)
+	if balance.Confirmed != 5*crypto.DecimalAmount(crypto.VLX) || balance.Total != balance.Confirmed {
+		t.Errorf("balance got = %+v", balance)
+	}
+	unspents := make([]unspentResult, 0)
+	runJSON(t, &unspents, "-node", srv.URL, "unspent", key.Address)
+	if len(unspents) != 1 || unspents[0].Value != 5*uint64(crypto.VLX) {
+		t.Errorf("unspent got = %+v", unspents)
+	}
+
+	txJSON, err := runTool(t, "", "-node", srv.URL, "build", "-key", key.PrivateKey, "-to", receiver.Address, "-amount", "1.5 VLX")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx := crypto.Tx{}
+	if err := json.Unmarshal([]byte(txJSON), &tx); err != nil {
+		t.Fatal(err)
+	}
+	if len(tx.Outputs) != 3 || tx.Outputs[1].Value != 150000000 || tx.Outputs[0].Value != httpClient.DefaultCommission {
+		t.Errorf("build got outputs %+v", tx.Outputs)
+	}
+
+	binaryTx := tx.EncodeGoVelas()
+	for _, in := range []string{txJSON, hex.EncodeToString(binaryTx), string(binaryTx)} {
+		inspection := crypto.TxInspection{}
+		out, err := runTool(t, in, "-json", "inspect")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal([]byte(out), &inspection); err != nil {
+			t.Fatal(err)
+		}
+		if !inspection.Valid() || inspection.ComputedHash != tx.Hash || inspection.ImpliedCommission != httpClient.DefaultCommission {
+			t.Errorf("inspect got = %+v", inspection)
+		}
+	}
+	tampered := tx
+	tampered.Outputs = append([]crypto.TransactionOutput{}, tx.Outputs...)
+	tampered.Outputs[1].Value++
+	tamperedJSON, _ := json.Marshal(&tampered)
+	out, err := runTool(t, string(tamperedJSON), "inspect")
+	if err != nil || !strings.Contains(out, "invalid signature of input 0") || !strings.Contains(out, "doesn't match computed hash") {
+		t.Errorf("inspect of tampered transaction got = %s, error = %v", out, err)
+	}
+
+	resigned, err := runTool(t, txJSON, "sign", "-key", key.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resigned != txJSON {
+		t.Errorf("sign of signed transaction changed it")
+	}
+	if _, err := runTool(t, txJSON, "sign", "-key", receiver.PrivateKey); err == nil {
+		t.Errorf("sign with another key must fail")
+	}
+	if out, err := runTool(t, txJSON, "-node", srv.URL, "validate"); err != nil || !strings.Contains(out, "valid") {
+		t.Errorf("validate got = %s, error = %v", out, err)
+	}
+
+	srv.SetAutoMine(true)
+	published := publishResult{}
+	out, err = runTool(t, txJSON, "-json", "-node", srv.URL, "publish", "-confirmations", "1", "-timeout", "5s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(out), &published); err != nil {
+		t.Fatal(err)
+	}
+	if published.Hash != tx.Hash || published.Block != srv.TipHash() || published.Confirmations != 1 {
+		t.Errorf("publish got = %+v", published)
+	}
+	if _, err := runTool(t, txJSON, "-node", srv.URL, "publish"); err == nil {
+		t.Errorf("publish of spent outputs must fail")
+	}
+
+	balance = balanceResult{}
+	runJSON(t, &balance, "-node", srv.URL, "balance", receiver.Address)
+	if balance.Confirmed != 150000000 {
+		t.Errorf("balance of receiver got = %+v", balance)
+	}
+	block, err := runTool(t, "", "-node", srv.URL, "block")
+	if err != nil || !strings.Contains(block, tx.Hash.String()) {
+		t.Errorf("block got = %s, error = %v", block, err)
+	}
+	block, err = runTool(t, "", "-node", srv.URL, "block", "-height", "1")
+	if err != nil || !strings.Contains(block, "height:          1") {
+		t.Errorf("block by height got = %s, error = %v", block, err)
+	}
+	block, err = runTool(t, "", "-node", srv.URL, "block", srv.TipHash().String())
+	if err != nil || !strings.Contains(block, srv.TipHash().String()) {
+		t.Errorf("block by hash got = %s, error = %v", block, err)
+	}
+	info, err := runTool(t, "", "-node", srv.URL, "info")
+	if err != nil || !strings.Contains(info, srv.TipHash().String()) {
+		t.Errorf("info got = %s, error = %v", info, err)
+	}
+
+	for _, args := range [][]string{
+		{"-node", srv.URL, "build", "-key", key.PrivateKey, "-to", receiver.Address, "-amount", "1.123456789"},
+		{"-node", srv.URL, "build", "-key", key.PrivateKey, "-to", "invalid", "-amount", "1"},
+		{"-node", srv.URL, "build", "-key", key.PrivateKey, "-to", receiver.Address, "-amount", "100"},
+		{"-node", srv.URL, "build", "-key", key.PrivateKey, "-to", receiver.Address},
+		{"-node", srv.URL, "block", "-height", "100"},
+		{"-node", srv.URL, "block", "invalid"},
+	} {
+		if _, err := runTool(t, "", args...); err == nil {
+			t.Errorf("run(%v) must fail", args)
+		}
+	}
+}
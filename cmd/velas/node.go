@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"github.com/go-errors/errors"
+	"github.com/velas/GoVelas/crypto"
+	"github.com/velas/GoVelas/httpClient"
+	"strconv"
+)
+
+func runInfo(e *env, args []string) error {
+	fs := e.flagSet("info")
+	if err := parseArgs(fs, args, 0, 0); err != nil {
+		return err
+	}
+	client, err := e.client()
+	if err != nil {
+		return err
+	}
+	info, err := client.NodeInfo()
+	if err != nil {
+		return err
+	}
+	lines := []string{field("synchronized", info.IsSync)}
+	if info.P2PInfo != nil {
+		lines = append(lines, field("id", info.P2PInfo.ID), field("name", info.P2PInfo.Name))
+	}
+	if info.Blockchain != nil {
+		lines = append(lines,
+			field("height", info.Blockchain.Height),
+			field("current hash", info.Blockchain.CurrentHash),
+			field("current epoch", info.Blockchain.CurrentEpoch),
+		)
+	}
+	lines = append(lines, field("peers", len(info.P2PPeers)))
+	return e.print(info, lines...)
+}
+
+// Balance in output of balance command
+type balanceResult struct {
+	Address         string               `json:"address"`
+	Confirmed       crypto.DecimalAmount `json:"confirmed"`
+	Staked          crypto.DecimalAmount `json:"staked"`
+	PendingIncoming crypto.DecimalAmount `json:"pending_incoming"`
+	PendingOutgoing crypto.DecimalAmount `json:"pending_outgoing"`
+	Total           crypto.DecimalAmount `json:"total"`
+}
+
+func runBalance(e *env, args []string) error {
+	fs := e.flagSet("balance")
+	if err := parseArgs(fs, args, 1, 1); err != nil {
+		return err
+	}
+	client, err := e.client()
+	if err != nil {
+		return err
+	}
+	if !crypto.IsWalletAddress(fs.Arg(0)) {
+		return errors.Errorf("invalid address %s", fs.Arg(0))
+	}
+	// pending transactions are found by one poll of watcher
+	watcher := client.NewWatcher(httpClient.WatcherOptions{}, fs.Arg(0))
+	if err := watcher.Poll(); err != nil {
+		return err
+	}
+	summary, err := client.GetBalanceSummary(fs.Arg(0), httpClient.BalanceOptions{Watcher: watcher})
+	if err != nil {
+		return err
+	}
+	total, err := summary.Total()
+	if err != nil {
+		return err
+	}
+	result := balanceResult{
+		Address:         fs.Arg(0),
+		Confirmed:       crypto.DecimalAmount(summary.Confirmed),
+		Staked:          crypto.DecimalAmount(summary.Staked),
+		PendingIncoming: crypto.DecimalAmount(summary.PendingIncoming),
+		PendingOutgoing: crypto.DecimalAmount(summary.PendingOutgoing),
+		Total:           crypto.DecimalAmount(total),
+	}
+	return e.print(result,
+		field("address", result.Address),
+		field("confirmed", summary.Confirmed),
+		field("staked", summary.Staked),
+		field("pending in", summary.PendingIncoming),
+		field("pending out", summary.PendingOutgoing),
+		field("total", total),
+	)
+}
+
+// Unspent output in output of unspent command
+type unspentResult struct {
+	Hash   crypto.Hash          `json:"hash"`
+	Index  uint32               `json:"index"`
+	Value  uint64               `json:"value"`
+	Amount crypto.DecimalAmount `json:"amount"`
+}
+
+func runUnspent(e *env, args []string) error {
+	fs := e.flagSet("unspent")
+	withStakes := fs.Bool("staking", false, "include staking outputs")
+	if err := parseArgs(fs, args, 1, 1); err != nil {
+		return err
+	}
+	client, err := e.client()
+	if err != nil {
+		return err
+	}
+	var unspents []crypto.TransactionInputOutpoint
+	if *withStakes {
+		unspents, err = client.Wallet.GetUnspentForStaking(fs.Arg(0))
+	} else {
+		unspents, err = client.Wallet.GetUnspent(fs.Arg(0))
+	}
+	if err != nil {
+		return err
+	}
+	results := make([]unspentResult, 0, len(unspents))
+	lines := make([]string, 0, len(unspents))
+	for _, unspent := range unspents {
+		results = append(results, unspentResult{
+			Hash:   unspent.Hash,
+			Index:  unspent.Index,
+			Value:  unspent.Value,
+			Amount: crypto.DecimalAmount(unspent.Value),
+		})
+		lines = append(lines, fmt.Sprintf("%s:%d %s", unspent.Hash, unspent.Index, crypto.Amount(unspent.Value)))
+	}
+	return e.print(results, lines...)
+}
+
+func runBlock(e *env, args []string) error {
+	fs := e.flagSet("block")
+	height := fs.Int64("height", -1, "height of block")
+	if err := parseArgs(fs, args, 0, 1); err != nil {
+		return err
+	}
+	client, err := e.client()
+	if err != nil {
+		return err
+	}
+	var block *httpClient.BlockResponse
+	switch {
+	case fs.NArg() == 1 && *height >= 0:
+		return errors.Errorf("either hash or height must be set, not both")
+	case fs.NArg() == 1:
+		var hash crypto.Hash
+		if hash, err = crypto.ParseHash(fs.Arg(0)); err == nil {
+			block, err = client.Block.GetByHash(hash)
+		}
+	case *height >= 0:
+		block, err = client.Block.GetByHeight(uint32(*height))
+	default:
+		block, err = client.Block.GetLatest()
+	}
+	if err != nil {
+		return err
+	}
+
+	header := block.Header
+	lines := []string{
+		field("hash", header.Hash),
+		field("height", header.Height),
+		field("previous", header.PrevBlock),
+		field("merkle root", header.MerkleRoot),
+		field("timestamp", header.Timestamp),
+		field("version", header.Version),
+		field("transactions", header.TxnCount),
+	}
+	for _, advice := range block.Advices {
+		lines = append(lines, field("advice", advice.PublicKey))
+	}
+	for i, tx := range block.Transactions {
+		lines = append(lines, field("tx "+strconv.Itoa(i), tx.Hash))
+	}
+	return e.print(block, lines...)
+}
@@ -0,0 +1,277 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/go-errors/errors"
+	"github.com/velas/GoVelas/crypto"
+	"github.com/velas/GoVelas/httpClient"
+	"io/ioutil"
+	"strconv"
+	"time"
+)
+
+// Flag of amount in VLX, like "12.5" or "12.5 VLX"
+type amountFlag struct {
+	amount crypto.Amount
+	set    bool
+}
+
+// String return flag value for usage
+func (af *amountFlag) String() string {
+	if !af.set {
+		return ""
+	}
+	return af.amount.String()
+}
+
+// Set parse flag value
+func (af *amountFlag) Set(value string) error {
+	amount, err := crypto.ParseAmount(value)
+	if err != nil {
+		return err
+	}
+	af.amount = amount
+	af.set = true
+	return nil
+}
+
+// inFlag add flag of file with transaction
+func inFlag(fs *flag.FlagSet) *string {
+	return fs.String("in", "", "file with transaction in json or GoVelas binary form, raw or in hex, standard input is read if it isn't set")
+}
+
+// readTx read transaction in json or GoVelas binary form from file or standard input, see crypto.DecodeTx
+func (e *env) readTx(path string) (*crypto.Tx, error) {
+	var data []byte
+	var err error
+	if path == "" {
+		data, err = ioutil.ReadAll(e.in)
+	} else {
+		data, err = ioutil.ReadFile(path)
+	}
+	if err != nil {
+		return nil, errors.New(err)
+	}
+	return crypto.DecodeTx(data)
+}
+
+// printTx write transaction in json, it is the format of other transaction commands
+func (e *env) printTx(tx *crypto.Tx) error {
+	data, err := json.MarshalIndent(tx, "", "  ")
+	if err != nil {
+		return errors.New(err)
+	}
+	_, err = e.out.Write(append(data, '\n'))
+	return err
+}
+
+func runBuild(e *env, args []string) error {
+	fs := e.flagSet("build")
+	kf := keyFlags{}
+	kf.register(fs)
+	to := fs.String("to", "", "address of receiver")
+	amount := &amountFlag{}
+	fs.Var(amount, "amount", "amount in VLX, like 12.5")
+	commission := &amountFlag{amount: httpClient.DefaultCommission}
+	fs.Var(commission, "commission", "commission in VLX, default "+crypto.Amount(httpClient.DefaultCommission).String())
+	lockTime := fs.Uint("locktime", 0, "lock time, height of block or unix time")
+	if err := parseArgs(fs, args, 0, 0); err != nil {
+		return err
+	}
+	if *to == "" || !amount.set {
+		fs.Usage()
+		return errUsage
+	}
+	if !crypto.IsWalletAddress(*to) {
+		return errors.Errorf("invalid address %s", *to)
+	}
+	hd, err := kf.load()
+	if err != nil {
+		return err
+	}
+	wallet, err := hd.ToWallet()
+	if err != nil {
+		return err
+	}
+	client, err := e.client()
+	if err != nil {
+		return err
+	}
+	unspents, err := client.Wallet.GetUnspent(wallet.Base58Address)
+	if err != nil {
+		return err
+	}
+	total, err := amount.amount.Add(commission.amount)
+	if err != nil {
+		return err
+	}
+	selected, err := crypto.SelectUnspents(unspents, total)
+	if err != nil {
+		return err
+	}
+	receivers := []crypto.Receiver{{Wallet: *to, Amount: amount.amount}}
+	opts := crypto.TxOptions{LockTime: uint32(*lockTime)}
+	tx, err := crypto.NewTransactionWithOptions(selected, *hd, wallet.Base58Address, receivers, commission.amount, opts)
+	if err != nil {
+		return err
+	}
+	return e.printTx(tx)
+}
+
+func runSign(e *env, args []string) error {
+	fs := e.flagSet("sign")
+	kf := keyFlags{}
+	kf.register(fs)
+	in := inFlag(fs)
+	if err := parseArgs(fs, args, 0, 0); err != nil {
+		return err
+	}
+	hd, err := kf.load()
+	if err != nil {
+		return err
+	}
+	tx, err := e.readTx(*in)
+	if err != nil {
+		return err
+	}
+	if err := tx.Sign(*hd); err != nil {
+		return err
+	}
+	return e.printTx(tx)
+}
+
+// Result of validate and publish commands
+type publishResult struct {
+	Hash          crypto.Hash `json:"hash"`
+	Result        string      `json:"result,omitempty"`
+	Block         crypto.Hash `json:"block,omitempty"`
+	Confirmations uint32      `json:"confirmations"`
+}
+
+func runValidate(e *env, args []string) error {
+	fs := e.flagSet("validate")
+	in := inFlag(fs)
+	if err := parseArgs(fs, args, 0, 0); err != nil {
+		return err
+	}
+	tx, err := e.readTx(*in)
+	if err != nil {
+		return err
+	}
+	client, err := e.client()
+	if err != nil {
+		return err
+	}
+	if err := client.Tx.Validate(*tx); err != nil {
+		return err
+	}
+	return e.print(publishResult{Hash: tx.Hash, Result: "valid"}, field("hash", tx.Hash), field("result", "valid"))
+}
+
+func runPublish(e *env, args []string) error {
+	fs := e.flagSet("publish")
+	in := inFlag(fs)
+	confirmations := fs.Uint("confirmations", 0, "wait confirmations of transaction")
+	timeout := fs.Duration("timeout", 5*time.Minute, "maximum time of waiting confirmations")
+	if err := parseArgs(fs, args, 0, 0); err != nil {
+		return err
+	}
+	tx, err := e.readTx(*in)
+	if err != nil {
+		return err
+	}
+	client, err := e.client()
+	if err != nil {
+		return err
+	}
+
+	result := publishResult{Hash: tx.Hash}
+	if *confirmations == 0 {
+		if err := client.Tx.Publish(*tx); err != nil {
+			return err
+		}
+	} else {
+		ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+		defer cancel()
+		published, err := client.Tx.PublishAndWait(ctx, *tx, uint32(*confirmations))
+		if err != nil {
+			return err
+		}
+		result.Result = published.Result
+		if published.Tx != nil {
+			result.Block = published.Tx.Block
+			result.Confirmations = published.Tx.Confirmed
+		}
+	}
+
+	lines := []string{field("hash", result.Hash)}
+	if !result.Block.IsEmpty() {
+		lines = append(lines, field("block", result.Block), field("confirmations", result.Confirmations))
+	}
+	return e.print(result, lines...)
+}
+
+func runInspect(e *env, args []string) error {
+	fs := e.flagSet("inspect")
+	in := inFlag(fs)
+	if err := parseArgs(fs, args, 0, 0); err != nil {
+		return err
+	}
+	tx, err := e.readTx(*in)
+	if err != nil {
+		return err
+	}
+	ti := crypto.InspectTx(tx)
+
+	lines := []string{
+		field("hash", ti.Hash),
+		field("computed hash", ti.ComputedHash),
+		field("version", ti.Version),
+		field("lock time", ti.LockTime),
+		field("size", ti.Size),
+	}
+	for i, input := range ti.Inputs {
+		lines = append(lines,
+			field("input "+strconv.Itoa(i), fmt.Sprintf("%s:%d %s", input.PreviousOutput.Hash, input.PreviousOutput.Index, input.Amount)),
+			field("  address", input.Address),
+			field("  public key", input.PublicKey),
+			field("  key address", input.KeyAddress),
+			field("  sequence", input.Sequence),
+			field("  signature", validity(input.SignatureValid)),
+		)
+	}
+	for i, output := range ti.Outputs {
+		lines = append(lines, field("output "+strconv.Itoa(i), fmt.Sprintf("%s %s", output.Purpose, output.Amount)))
+		if output.Address != "" {
+			lines = append(lines, field("  address", output.Address))
+		}
+		if output.NodeID != "" {
+			lines = append(lines, field("  node id", output.NodeID))
+		}
+		if output.Payload != "" {
+			lines = append(lines, field("  payload", output.Payload))
+		}
+	}
+	lines = append(lines,
+		field("total in", ti.TotalIn),
+		field("total out", ti.TotalOut),
+		field("commission", ti.Commission),
+		field("implied", ti.ImpliedCommission),
+	)
+	for _, problem := range ti.Problems {
+		lines = append(lines, field("problem", problem))
+	}
+	lines = append(lines, field("result", validity(ti.Valid())))
+	return e.print(ti, lines...)
+}
+
+// validity return text of check result
+func validity(valid bool) string {
+	if valid {
+		return "valid"
+	}
+	return "invalid"
+}
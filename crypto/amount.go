@@ -0,0 +1,172 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/go-errors/errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Count of decimal digits of VLX, amounts in transactions are in base units
+const AmountDecimals = 8
+
+// One VLX in base units
+const VLX Amount = 100000000
+
+// Error of Add, when sum doesn't fit into Amount
+var ErrAmountOverflow = errors.New("Amount overflow")
+
+// Error of Sub, when subtrahend is greater than amount
+var ErrNegativeAmount = errors.New("Amount can't be negative")
+
+// Amount of VLX in base units. It is encoded to json as number of base units, like values of node, and decoded from
+// number of base units or from decimal string in VLX, see ParseAmount
+type Amount uint64
+
+// Add return sum of amounts or ErrAmountOverflow
+func (a Amount) Add(b Amount) (Amount, error) {
+	if a > math.MaxUint64-b {
+		return 0, ErrAmountOverflow
+	}
+	return a + b, nil
+}
+
+// SumUnspents return total value of unspents or ErrAmountOverflow
+func SumUnspents(unspents []TransactionInputOutpoint) (Amount, error) {
+	total := Amount(0)
+	for _, unspent := range unspents {
+		var err error
+		if total, err = total.Add(Amount(unspent.Value)); err != nil {
+			return 0, err
+		}
+	}
+	return total, nil
+}
+
+// Mul return amount multiplied by n or ErrAmountOverflow
+func (a Amount) Mul(n uint64) (Amount, error) {
+	if n != 0 && uint64(a) > math.MaxUint64/n {
+		return 0, ErrAmountOverflow
+	}
+	return a * Amount(n), nil
+}
+
+// Sub return difference of amounts or ErrNegativeAmount
+func (a Amount) Sub(b Amount) (Amount, error) {
+	if b > a {
+		return 0, ErrNegativeAmount
+	}
+	return a - b, nil
+}
+
+// ParseAmount parse decimal string in VLX, like "12.5", "12.5 VLX" or "0.00000001vlx". Fraction can't have more than
+// AmountDecimals digits
+func ParseAmount(s string) (Amount, error) {
+	value := strings.TrimSpace(s)
+	if len(value) >= 3 && strings.EqualFold(value[len(value)-3:], "VLX") {
+		value = strings.TrimSpace(value[:len(value)-3])
+	}
+	whole, fraction := value, ""
+	if dot := strings.IndexByte(value, '.'); dot >= 0 {
+		whole, fraction = value[:dot], value[dot+1:]
+		if fraction == "" {
+			return 0, errors.Errorf("Invalid amount %q", s)
+		}
+	}
+	if whole == "" || !isDigits(whole) || !isDigits(fraction) {
+		return 0, errors.Errorf("Invalid amount %q", s)
+	}
+	if len(fraction) > AmountDecimals {
+		return 0, errors.Errorf("Amount %q has more than %d decimals", s, AmountDecimals)
+	}
+
+	vlx, err := strconv.ParseUint(whole, 10, 64)
+	if err != nil || vlx > math.MaxUint64/uint64(VLX) {
+		return 0, ErrAmountOverflow
+	}
+	units := uint64(0)
+	if fraction != "" {
+		units, err = strconv.ParseUint(fraction+strings.Repeat("0", AmountDecimals-len(fraction)), 10, 64)
+		if err != nil {
+			return 0, errors.New(err)
+		}
+	}
+	return Amount(vlx * uint64(VLX)).Add(Amount(units))
+}
+
+// isDigits check that string contains only decimal digits
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Format return amount in VLX with fixed count of decimals, extra digits are truncated. Count of decimals is limited
+// by AmountDecimals
+func (a Amount) Format(decimals int) string {
+	if decimals < 0 {
+		decimals = 0
+	}
+	if decimals > AmountDecimals {
+		decimals = AmountDecimals
+	}
+	whole := strconv.FormatUint(uint64(a/VLX), 10)
+	if decimals == 0 {
+		return whole
+	}
+	fraction := strconv.FormatUint(uint64(a%VLX), 10)
+	fraction = strings.Repeat("0", AmountDecimals-len(fraction)) + fraction
+	return whole + "." + fraction[:decimals]
+}
+
+// String return amount in VLX without trailing zeros of fraction, like "12.5 VLX"
+func (a Amount) String() string {
+	return a.decimal() + " VLX"
+}
+
+// decimal return amount in VLX without trailing zeros of fraction and without unit
+func (a Amount) decimal() string {
+	formatted := strings.TrimRight(a.Format(AmountDecimals), "0")
+	return strings.TrimSuffix(formatted, ".")
+}
+
+// UnmarshalJSON decode amount from number of base units or from decimal string in VLX
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(data, []byte(`"`)) {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return errors.New(err)
+		}
+		amount, err := ParseAmount(s)
+		if err != nil {
+			return err
+		}
+		*a = amount
+		return nil
+	}
+	var units uint64
+	if err := json.Unmarshal(data, &units); err != nil {
+		return errors.New(err)
+	}
+	*a = Amount(units)
+	return nil
+}
+
+// Amount, which is encoded to json as decimal string in VLX without unit, like "12.5". It is used for output to
+// users, decoding is the same as for Amount
+type DecimalAmount Amount
+
+// MarshalJSON encode amount to decimal string in VLX
+func (d DecimalAmount) MarshalJSON() ([]byte, error) {
+	return json.Marshal(Amount(d).decimal())
+}
+
+// UnmarshalJSON decode amount like Amount
+func (d *DecimalAmount) UnmarshalJSON(data []byte) error {
+	return (*Amount)(d).UnmarshalJSON(data)
+}
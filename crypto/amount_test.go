@@ -0,0 +1,152 @@
+package crypto
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    Amount
+		wantErr bool
+	}{
+		{name: "Whole", s: "12", want: 12 * VLX, wantErr: false},
+		{name: "Fraction", s: "12.5", want: 1250000000, wantErr: false},
+		{name: "Unit", s: "12.5 VLX", want: 1250000000, wantErr: false},
+		{name: "Lower case unit", s: " 0.00000001vlx ", want: 1, wantErr: false},
+		{name: "Zero", s: "0", want: 0, wantErr: false},
+		{name: "Max", s: "184467440737.09551615", want: math.MaxUint64, wantErr: false},
+		{name: "Overflow", s: "184467440737.09551616", wantErr: true},
+		{name: "Whole overflow", s: "184467440738", wantErr: true},
+		{name: "Too many decimals", s: "0.000000001", wantErr: true},
+		{name: "Negative", s: "-1", wantErr: true},
+		{name: "Empty fraction", s: "1.", wantErr: true},
+		{name: "Empty whole", s: ".5", wantErr: true},
+		{name: "Only unit", s: "VLX", wantErr: true},
+		{name: "Exponent", s: "1e8", wantErr: true},
+		{name: "Empty", s: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAmount(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseAmount() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseAmount() got = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAmount_Format(t *testing.T) {
+	tests := []struct {
+		name       string
+		amount     Amount
+		decimals   int
+		want       string
+		wantString string
+	}{
+		{name: "Whole", amount: 12 * VLX, decimals: 2, want: "12.00", wantString: "12 VLX"},
+		{name: "Fraction", amount: 1250000000, decimals: 2, want: "12.50", wantString: "12.5 VLX"},
+		{name: "Truncated", amount: 1259999999, decimals: 2, want: "12.59", wantString: "12.59999999 VLX"},
+		{name: "Base unit", amount: 1, decimals: 8, want: "0.00000001", wantString: "0.00000001 VLX"},
+		{name: "No decimals", amount: 1250000000, decimals: 0, want: "12", wantString: "12.5 VLX"},
+		{name: "Too many decimals", amount: 1250000000, decimals: 10, want: "12.50000000", wantString: "12.5 VLX"},
+		{name: "Zero", amount: 0, decimals: 1, want: "0.0", wantString: "0 VLX"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.amount.Format(tt.decimals); got != tt.want {
+				t.Errorf("Format() got = %s, want %s", got, tt.want)
+			}
+			if got := tt.amount.String(); got != tt.wantString {
+				t.Errorf("String() got = %s, want %s", got, tt.wantString)
+			}
+			parsed, err := ParseAmount(tt.amount.String())
+			if err != nil || parsed != tt.amount {
+				t.Errorf("ParseAmount() of String() got = %d, error = %v", parsed, err)
+			}
+		})
+	}
+}
+
+func TestAmount_AddSub(t *testing.T) {
+	if got, err := Amount(2).Add(3); err != nil || got != 5 {
+		t.Errorf("Add() got = %d, error = %v", got, err)
+	}
+	if _, err := Amount(math.MaxUint64).Add(1); err != ErrAmountOverflow {
+		t.Errorf("Add() error = %v, want ErrAmountOverflow", err)
+	}
+	if got, err := Amount(5).Sub(3); err != nil || got != 2 {
+		t.Errorf("Sub() got = %d, error = %v", got, err)
+	}
+	if _, err := Amount(3).Sub(5); err != ErrNegativeAmount {
+		t.Errorf("Sub() error = %v, want ErrNegativeAmount", err)
+	}
+	if got, err := Amount(4).Mul(3); err != nil || got != 12 {
+		t.Errorf("Mul() got = %d, error = %v", got, err)
+	}
+	if got, err := Amount(math.MaxUint64).Mul(0); err != nil || got != 0 {
+		t.Errorf("Mul() by zero got = %d, error = %v", got, err)
+	}
+	if _, err := Amount(math.MaxUint64 / 2).Mul(3); err != ErrAmountOverflow {
+		t.Errorf("Mul() error = %v, want ErrAmountOverflow", err)
+	}
+}
+
+func TestSumUnspents(t *testing.T) {
+	unspents := []TransactionInputOutpoint{{Value: 2}, {Value: 3}}
+	if got, err := SumUnspents(unspents); err != nil || got != 5 {
+		t.Errorf("SumUnspents() got = %d, error = %v", got, err)
+	}
+	overflow := append(unspents, TransactionInputOutpoint{Value: math.MaxUint64})
+	if _, err := SumUnspents(overflow); err != ErrAmountOverflow {
+		t.Errorf("SumUnspents() error = %v, want ErrAmountOverflow", err)
+	}
+	if _, err := NewTransactionManyRecievers(overflow, HD{}, "", nil, 0); err != ErrAmountOverflow {
+		t.Errorf("NewTransactionManyRecievers() error = %v, want ErrAmountOverflow", err)
+	}
+}
+
+func TestAmount_JSON(t *testing.T) {
+	type values struct {
+		Amount  Amount        `json:"amount"`
+		Decimal DecimalAmount `json:"decimal"`
+	}
+	data, err := json.Marshal(values{Amount: 1250000000, Decimal: 1250000000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"amount":1250000000,"decimal":"12.5"}` {
+		t.Errorf("Marshal() got = %s", data)
+	}
+
+	tests := []struct {
+		name    string
+		data    string
+		want    values
+		wantErr bool
+	}{
+		{name: "Encoded", data: string(data), want: values{Amount: 1250000000, Decimal: 1250000000}, wantErr: false},
+		{name: "Base units", data: `{"amount":1,"decimal":2}`, want: values{Amount: 1, Decimal: 2}, wantErr: false},
+		{name: "VLX strings", data: `{"amount":"1 VLX","decimal":"0.5VLX"}`, want: values{Amount: VLX, Decimal: 50000000}, wantErr: false},
+		{name: "Invalid string", data: `{"amount":"1,5"}`, wantErr: true},
+		{name: "Negative", data: `{"amount":-1}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := values{}
+			err := json.Unmarshal([]byte(tt.data), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Unmarshal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("Unmarshal() got = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package crypto
+
+import (
+	"github.com/go-errors/errors"
+	"sort"
+)
+
+// SelectUnspents choose outputs for spending target amount(amount with commission). If one output covers the target,
+// the smallest of such outputs is used, else outputs are taken from the largest, so transaction has less inputs.
+// Returned outputs keep the order of unspents
+func SelectUnspents(unspents []TransactionInputOutpoint, target Amount) ([]TransactionInputOutpoint, error) {
+	total, err := SumUnspents(unspents)
+	if err != nil {
+		return nil, err
+	}
+	if total < target {
+		return nil, errors.Errorf("Insufficient funds, total amount %d, required %d", total, target)
+	}
+
+	best := -1
+	for i, unspent := range unspents {
+		if Amount(unspent.Value) >= target && (best == -1 || unspent.Value < unspents[best].Value) {
+			best = i
+		}
+	}
+	if best != -1 {
+		return []TransactionInputOutpoint{unspents[best]}, nil
+	}
+
+	order := make([]int, len(unspents))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return unspents[order[i]].Value > unspents[order[j]].Value
+	})
+	selected := make(map[int]bool)
+	// sum of selected outputs isn't greater than total, so it doesn't overflow
+	sum := Amount(0)
+	for _, i := range order {
+		selected[i] = true
+		sum += Amount(unspents[i].Value)
+		if sum >= target {
+			break
+		}
+	}
+
+	result := make([]TransactionInputOutpoint, 0, len(selected))
+	for i, unspent := range unspents {
+		if selected[i] {
+			result = append(result, unspent)
+		}
+	}
+	return result, nil
+}
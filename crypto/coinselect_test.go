@@ -0,0 +1,66 @@
+package crypto
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSelectUnspents(t *testing.T) {
+	unspent := func(index uint32, value uint64) TransactionInputOutpoint {
+		return TransactionInputOutpoint{Hash: DHASH([]byte("unspents")), Index: index, Value: value}
+	}
+	unspents := []TransactionInputOutpoint{unspent(0, 100), unspent(1, 500), unspent(2, 300), unspent(3, 50)}
+	type args struct {
+		unspents []TransactionInputOutpoint
+		target   Amount
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    []TransactionInputOutpoint
+		wantErr bool
+	}{
+		{
+			name:    "smallest single output",
+			args:    args{unspents: unspents, target: 250},
+			want:    []TransactionInputOutpoint{unspent(2, 300)},
+			wantErr: false,
+		},
+		{
+			name:    "exact single output",
+			args:    args{unspents: unspents, target: 500},
+			want:    []TransactionInputOutpoint{unspent(1, 500)},
+			wantErr: false,
+		},
+		{
+			name:    "largest first",
+			args:    args{unspents: unspents, target: 850},
+			want:    []TransactionInputOutpoint{unspent(0, 100), unspent(1, 500), unspent(2, 300)},
+			wantErr: false,
+		},
+		{
+			name:    "all outputs",
+			args:    args{unspents: unspents, target: 950},
+			want:    unspents,
+			wantErr: false,
+		},
+		{
+			name:    "insufficient funds",
+			args:    args{unspents: unspents, target: 951},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SelectUnspents(tt.args.unspents, tt.args.target)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SelectUnspents() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SelectUnspents() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
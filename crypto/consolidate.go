@@ -0,0 +1,73 @@
+package crypto
+
+import (
+	"github.com/go-errors/errors"
+	"sort"
+)
+
+// Self-transfer transaction of consolidation plan, it merges several outputs of wallet into one
+type ConsolidationBatch struct {
+	Unspents   []TransactionInputOutpoint // spent outputs
+	Amount     Amount                     // value of new output of wallet
+	Commission Amount
+	Tx         *Tx // signed transaction
+}
+
+// PlanConsolidation group unspents of key into self-transfer transactions with at most maxInputs inputs. The smallest
+// outputs are merged first, batches with one input or with commission not less than their value are skipped, so plan
+// can be empty. Batches spend different outputs and can be published in any order
+func PlanConsolidation(unspents []TransactionInputOutpoint, key HD, maxInputs int, rates FeeRates) ([]ConsolidationBatch, error) {
+	if maxInputs < 2 {
+		return nil, errors.Errorf("Maximum count of inputs must be at least 2, got %d", maxInputs)
+	}
+	wallet, err := key.ToWallet()
+	if err != nil {
+		return nil, err
+	}
+
+	sorted := make([]TransactionInputOutpoint, len(unspents))
+	copy(sorted, unspents)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Value < sorted[j].Value
+	})
+
+	batches := make([]ConsolidationBatch, 0)
+	for start := 0; start < len(sorted); start += maxInputs {
+		end := start + maxInputs
+		if end > len(sorted) {
+			end = len(sorted)
+		}
+		chunk := sorted[start:end]
+		if len(chunk) < 2 {
+			break
+		}
+		total, err := SumUnspents(chunk)
+		if err != nil {
+			return nil, err
+		}
+		sources := []SweepSource{{Key: key, Unspents: chunk}}
+		draft, err := newSweepTransaction(sources, wallet.Base58Address, total, 0)
+		if err != nil {
+			return nil, err
+		}
+		commission, err := rates.ForTx(draft)
+		if err != nil {
+			return nil, err
+		}
+		if commission >= total {
+			// dust outputs are not worth merging
+			continue
+		}
+		tx, err := newSweepTransaction(sources, wallet.Base58Address, total-commission, commission)
+		if err != nil {
+			return nil, err
+		}
+		batches = append(batches, ConsolidationBatch{
+			Unspents:   chunk,
+			Amount:     total - commission,
+			Commission: commission,
+			Tx:         tx,
+		})
+	}
+	return batches, nil
+}
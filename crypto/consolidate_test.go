@@ -0,0 +1,49 @@
+package crypto
+
+import (
+	"testing"
+)
+
+func TestPlanConsolidation(t *testing.T) {
+	hd, _ := GenerateHD()
+	unspents := make([]TransactionInputOutpoint, 0)
+	for i, value := range []uint64{3000000, 100, 5000000, 200, 4000000, 6000000, 7000000} {
+		unspents = append(unspents, TransactionInputOutpoint{
+			Hash:  DHASH([]byte{byte(i)}),
+			Index: uint32(i),
+			Value: value,
+		})
+	}
+	rates := FeeRates{Min: 1000000}
+
+	batches, err := PlanConsolidation(unspents, *hd, 3, rates)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// [100 200 3000000] [4000000 5000000 6000000], 7000000 is left alone
+	if len(batches) != 2 {
+		t.Fatalf("PlanConsolidation() got %d batches, want 2", len(batches))
+	}
+	wantAmounts := []Amount{3000300 - 1000000, 15000000 - 1000000}
+	for i, batch := range batches {
+		if len(batch.Unspents) != 3 {
+			t.Errorf("batch %d has %d inputs, want 3", i, len(batch.Unspents))
+		}
+		if batch.Amount != wantAmounts[i] || batch.Commission != 1000000 {
+			t.Errorf("batch %d amount = %d, commission = %d, want %d and 1000000", i, batch.Amount, batch.Commission, wantAmounts[i])
+		}
+		if err := batch.Tx.Verify(); err != nil {
+			t.Errorf("batch %d Verify() error = %v", i, err)
+		}
+	}
+
+	// dust batch is skipped
+	batches, _ = PlanConsolidation(unspents[:4], *hd, 2, FeeRates{Min: 3000000})
+	if len(batches) != 1 || batches[0].Amount != 8000000-3000000 {
+		t.Errorf("PlanConsolidation() with dust got %+v", batches)
+	}
+
+	if _, err := PlanConsolidation(unspents, *hd, 1, rates); err == nil {
+		t.Errorf("PlanConsolidation() with one input per batch must fail")
+	}
+}
@@ -0,0 +1,66 @@
+package crypto
+
+import (
+	"github.com/go-errors/errors"
+)
+
+// Rates for commission calculation. Commission is Base + PerByte*size + PerInput*inputs + PerOutput*outputs, but not
+// less than Min, size is length of serialized transaction
+type FeeRates struct {
+	Base      Amount `json:"base"`
+	PerByte   Amount `json:"per_byte"`
+	PerInput  Amount `json:"per_input"`
+	PerOutput Amount `json:"per_output"`
+	Min       Amount `json:"min"`
+}
+
+// Rates used by node now, it requires fixed commission
+var DefaultFeeRates = FeeRates{
+	Min: 1000000,
+}
+
+// Commission calculate commission for transaction with size in bytes, count of inputs and count of outputs.
+// ErrAmountOverflow is returned, if commission doesn't fit into Amount
+func (r FeeRates) Commission(size int, inputs int, outputs int) (Amount, error) {
+	commission := r.Base
+	for _, part := range []struct {
+		rate  Amount
+		count int
+	}{{r.PerByte, size}, {r.PerInput, inputs}, {r.PerOutput, outputs}} {
+		if part.count < 0 {
+			return 0, errors.Errorf("Negative count %d for commission", part.count)
+		}
+		value, err := part.rate.Mul(uint64(part.count))
+		if err != nil {
+			return 0, err
+		}
+		if commission, err = commission.Add(value); err != nil {
+			return 0, err
+		}
+	}
+	if commission < r.Min {
+		return r.Min, nil
+	}
+	return commission, nil
+}
+
+// ForTx calculate commission for signed transaction
+func (r FeeRates) ForTx(tx *Tx) (Amount, error) {
+	return r.Commission(tx.Size(), len(tx.Inputs), len(tx.Outputs))
+}
+
+// MaxSendAmount calculate amount, which can be sent from unspents to one receiver without change, and commission of
+// such transaction. It is the amount of sweep transaction, see NewSweepTransaction
+func MaxSendAmount(unspents []TransactionInputOutpoint, key HD, to string, rates FeeRates) (Amount, Amount, error) {
+	total, commission, err := sweepCommission([]SweepSource{{Key: key, Unspents: unspents}}, to, rates)
+	if err != nil {
+		return 0, 0, err
+	}
+	return total - commission, commission, nil
+}
+
+// NewTransactionAllFunds create transaction, which sends all unspents minus commission to the receiver, transaction
+// doesn't have change output
+func NewTransactionAllFunds(unspents []TransactionInputOutpoint, key HD, to string, rates FeeRates) (*Tx, error) {
+	return NewSweepTransaction([]SweepSource{{Key: key, Unspents: unspents}}, to, rates)
+}
@@ -0,0 +1,134 @@
+package crypto
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFeeRates_Commission(t *testing.T) {
+	type args struct {
+		size    int
+		inputs  int
+		outputs int
+	}
+	tests := []struct {
+		name    string
+		rates   FeeRates
+		args    args
+		want    Amount
+		wantErr bool
+	}{
+		{
+			name:  "Default rates",
+			rates: DefaultFeeRates,
+			args:  args{size: 226, inputs: 1, outputs: 3},
+			want:  1000000,
+		},
+		{
+			name:  "All rates",
+			rates: FeeRates{Base: 1000, PerByte: 10, PerInput: 100, PerOutput: 50, Min: 0},
+			args:  args{size: 226, inputs: 2, outputs: 3},
+			want:  1000 + 2260 + 200 + 150,
+		},
+		{
+			name:  "Minimum",
+			rates: FeeRates{PerByte: 10, Min: 5000},
+			args:  args{size: 226, inputs: 1, outputs: 2},
+			want:  5000,
+		},
+		{
+			name:    "Overflow of rate",
+			rates:   FeeRates{PerByte: math.MaxUint64 / 100},
+			args:    args{size: 226, inputs: 1, outputs: 2},
+			wantErr: true,
+		},
+		{
+			name:    "Overflow of sum",
+			rates:   FeeRates{Base: math.MaxUint64, PerOutput: 1},
+			args:    args{size: 226, inputs: 1, outputs: 2},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.rates.Commission(tt.args.size, tt.args.inputs, tt.args.outputs)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Commission() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Commission() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTx_Size(t *testing.T) {
+	hd, _ := GenerateHD()
+	wallet, _ := hd.ToWallet()
+	unspents := []TransactionInputOutpoint{
+		{Hash: DHASH([]byte("first")), Index: 0, Value: 5000000},
+		{Hash: DHASH([]byte("second")), Index: 1, Value: 7000000},
+	}
+	tx, err := NewTransaction(unspents, 1000, *hd, wallet.Base58Address, "VLa1hi77ZXD2BSWDD9wQe8vAhejXyS7vBM4", 1000000, NodeID{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// header, inputs with public key and signature, commission output, receiver and change outputs with address
+	want := 8 + 2*(44+4+32+64) + 12 + 2*(12+26)
+	if got := tx.Size(); got != want {
+		t.Errorf("Size() = %d, want %d", got, want)
+	}
+}
+
+func TestNewTransactionAllFunds(t *testing.T) {
+	hd, _ := GenerateHD()
+	unspents := []TransactionInputOutpoint{
+		{Hash: DHASH([]byte("first")), Index: 0, Value: 5000000},
+		{Hash: DHASH([]byte("second")), Index: 1, Value: 7000000},
+	}
+	rates := FeeRates{Base: 1000, PerByte: 100}
+	tests := []struct {
+		name           string
+		unspents       []TransactionInputOutpoint
+		wantCommission Amount
+		wantErr        bool
+	}{
+		{
+			name:           "Two inputs",
+			unspents:       unspents,
+			wantCommission: 1000 + 100*Amount(8+2*144+12+38),
+			wantErr:        false,
+		},
+		{
+			name:     "Commission is more than funds",
+			unspents: []TransactionInputOutpoint{{Hash: DHASH([]byte("small")), Index: 0, Value: 10000}},
+			wantErr:  true,
+		},
+		{
+			name:     "No unspents",
+			unspents: nil,
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx, err := NewTransactionAllFunds(tt.unspents, *hd, "VLa1hi77ZXD2BSWDD9wQe8vAhejXyS7vBM4", rates)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewTransactionAllFunds() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(tx.Outputs) != 2 {
+				t.Fatalf("NewTransactionAllFunds() outputs = %d, want commission and receiver", len(tx.Outputs))
+			}
+			if commission, _ := rates.ForTx(tx); tx.Outputs[0].Amount() != tt.wantCommission || commission != tt.wantCommission {
+				t.Errorf("NewTransactionAllFunds() commission = %d, want %d", tx.Outputs[0].Value, tt.wantCommission)
+			}
+			if tx.Outputs[0].Value+tx.Outputs[1].Value != 12000000 {
+				t.Errorf("NewTransactionAllFunds() outputs don't spend all funds")
+			}
+		})
+	}
+}
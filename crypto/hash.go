@@ -0,0 +1,62 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"github.com/go-errors/errors"
+)
+
+// Hash of transaction or block, it is encoded to json as hex string
+type Hash [32]byte
+
+// ParseHash decode hash from hex string, string must contain exactly 32 bytes
+func ParseHash(s string) (Hash, error) {
+	buf, err := hex.DecodeString(s)
+	if err != nil {
+		return Hash{}, errors.New(err)
+	}
+	if len(buf) != len(Hash{}) {
+		return Hash{}, errors.Errorf("Invalid hash length %d", len(buf))
+	}
+	hash := Hash{}
+	copy(hash[:], buf)
+	return hash, nil
+}
+
+// String return hash in hex
+func (h Hash) String() string {
+	return hex.EncodeToString(h[:])
+}
+
+// IsEmpty check that hash is zero, it is used for missing hashes, for example block of unconfirmed transaction
+func (h Hash) IsEmpty() bool {
+	return h == Hash{}
+}
+
+// Equal compare hashes
+func (h Hash) Equal(other Hash) bool {
+	return h == other
+}
+
+// MarshalJSON encode hash to hex string
+func (h Hash) MarshalJSON() ([]byte, error) {
+	return json.Marshal(h.String())
+}
+
+// UnmarshalJSON decode hash from hex string, empty string and null are decoded to empty hash
+func (h *Hash) UnmarshalJSON(data []byte) error {
+	var s *string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return errors.New(err)
+	}
+	if s == nil || *s == "" {
+		*h = Hash{}
+		return nil
+	}
+	hash, err := ParseHash(*s)
+	if err != nil {
+		return err
+	}
+	*h = hash
+	return nil
+}
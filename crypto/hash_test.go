@@ -0,0 +1,68 @@
+package crypto
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseHash(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		wantErr bool
+	}{
+		{name: "Correct", s: "508e68a53e8e73e8d7b477e0c3c4dbed83a56899c8dcecabe12f79c42cbd1c87", wantErr: false},
+		{name: "Upper case", s: "508E68A53E8E73E8D7B477E0C3C4DBED83A56899C8DCECABE12F79C42CBD1C87", wantErr: false},
+		{name: "Short", s: "508e68a53e8e73e8", wantErr: true},
+		{name: "Long", s: "508e68a53e8e73e8d7b477e0c3c4dbed83a56899c8dcecabe12f79c42cbd1c8700", wantErr: true},
+		{name: "Not hex", s: "zz8e68a53e8e73e8d7b477e0c3c4dbed83a56899c8dcecabe12f79c42cbd1c87", wantErr: true},
+		{name: "Empty", s: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseHash(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseHash() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got.String() != "508e68a53e8e73e8d7b477e0c3c4dbed83a56899c8dcecabe12f79c42cbd1c87" {
+				t.Errorf("ParseHash() got = %s", got)
+			}
+		})
+	}
+}
+
+func TestHash_JSON(t *testing.T) {
+	hash := DHASH([]byte("hash"))
+	data, err := json.Marshal(struct{ Hash Hash }{hash})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"Hash":"`+hash.String()+`"}` {
+		t.Errorf("MarshalJSON() got = %s", data)
+	}
+
+	tests := []struct {
+		name    string
+		data    string
+		want    Hash
+		wantErr bool
+	}{
+		{name: "Hex", data: `"` + hash.String() + `"`, want: hash, wantErr: false},
+		{name: "Empty string", data: `""`, want: Hash{}, wantErr: false},
+		{name: "Null", data: `null`, want: Hash{}, wantErr: false},
+		{name: "Short", data: `"508e68"`, wantErr: true},
+		{name: "Number", data: `1`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DHASH([]byte("previous"))
+			err := json.Unmarshal([]byte(tt.data), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !got.Equal(tt.want) {
+				t.Errorf("UnmarshalJSON() got = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
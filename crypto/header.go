@@ -0,0 +1,95 @@
+package crypto
+
+import (
+	"bytes"
+	"github.com/GoKillers/libsodium-go/cryptosign"
+	"github.com/go-errors/errors"
+)
+
+// Block header with decoded fields
+type BlockHeader struct {
+	Type        uint32
+	Hash        Hash
+	Height      uint32
+	Version     uint32
+	PrevBlock   Hash
+	MerkleRoot  Hash
+	Timestamp   uint32
+	Bits        uint32
+	Nonce       uint32
+	Seed        []byte
+	TxnCount    uint32
+	AdviceCount uint32
+	Script      []byte // signature of block hash by block producer
+}
+
+// Sign sign stored hash with key of block producer. Hash is not calculated, byte layout of header for hash is not
+// confirmed against the node
+func (h *BlockHeader) Sign(key HD) error {
+	sig, err := cryptosign.CryptoSignDetached(h.Hash[:], key.privateKey)
+	if err != 0 {
+		return errors.Errorf("Error on sign message")
+	}
+	h.Script = sig
+	return nil
+}
+
+// VerifySignature check that block hash is signed by public key
+func (h *BlockHeader) VerifySignature(publicKey []byte) error {
+	if len(publicKey) != cryptosign.CryptoSignPublicKeyBytes() {
+		return errors.Errorf("Invalid public key length %d", len(publicKey))
+	}
+	if cryptosign.CryptoSignVerifyDetached(h.Script, h.Hash[:], publicKey) != 0 {
+		return errors.Errorf("Invalid signature of block %x", h.Hash)
+	}
+	return nil
+}
+
+// VerifyProducer check advice list and signature of stored hash. The block must be signed by one of advices and the signer must
+// be one of trusted keys, trusted keys are required. Public key of the producer is returned
+func (h *BlockHeader) VerifyProducer(advices [][]byte, trusted [][]byte) ([]byte, error) {
+	if len(trusted) == 0 {
+		return nil, errors.Errorf("Trusted keys of block producers are required")
+	}
+	producer, err := h.VerifyProducerUntrusted(advices)
+	if err != nil {
+		return nil, err
+	}
+	if !containsKey(trusted, producer) {
+		return nil, errors.Errorf("Block %x is signed by untrusted key %x", h.Hash, producer)
+	}
+	return producer, nil
+}
+
+// VerifyProducerUntrusted check advice list and that stored hash is signed by one of advices. Hash is not recalculated
+// from header fields, byte layout of header is not confirmed against the node, so the fields are not bound to the
+// signature. Advices are returned by the same node as the block, so the check doesn't protect from a dishonest node,
+// use VerifyProducer for it. Public key of the producer is returned
+func (h *BlockHeader) VerifyProducerUntrusted(advices [][]byte) ([]byte, error) {
+	if int(h.AdviceCount) != len(advices) {
+		return nil, errors.Errorf("Advice count %d doesn't match %d advices", h.AdviceCount, len(advices))
+	}
+	for i, advice := range advices {
+		for _, other := range advices[:i] {
+			if bytes.Equal(advice, other) {
+				return nil, errors.Errorf("Duplicate advice %x", advice)
+			}
+		}
+	}
+	for _, advice := range advices {
+		if h.VerifySignature(advice) == nil {
+			return advice, nil
+		}
+	}
+	return nil, errors.Errorf("Block %x is not signed by any advice", h.Hash)
+}
+
+// containsKey check that key is in the list
+func containsKey(keys [][]byte, key []byte) bool {
+	for _, k := range keys {
+		if bytes.Equal(k, key) {
+			return true
+		}
+	}
+	return false
+}
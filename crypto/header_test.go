@@ -0,0 +1,58 @@
+package crypto
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBlockHeader_VerifyProducer(t *testing.T) {
+	producer, _ := GenerateHD()
+	other, _ := GenerateHD()
+	header := BlockHeader{
+		Height:      10,
+		Version:     1,
+		PrevBlock:   DHASH([]byte("prev")),
+		MerkleRoot:  DHASH([]byte("root")),
+		Timestamp:   1570000000,
+		TxnCount:    1,
+		AdviceCount: 2,
+	}
+	header.Hash = DHASH([]byte("header"))
+	if err := header.Sign(*producer); err != nil {
+		t.Fatal(err)
+	}
+
+	advices := [][]byte{other.publicKey, producer.publicKey}
+	tests := []struct {
+		name    string
+		advices [][]byte
+		trusted [][]byte
+		wantErr bool
+	}{
+		{name: "Trusted producer", advices: advices, trusted: [][]byte{producer.publicKey}, wantErr: false},
+		{name: "Untrusted producer", advices: advices, trusted: [][]byte{other.publicKey}, wantErr: true},
+		{name: "Without trusted keys", advices: advices, trusted: nil, wantErr: true},
+		{name: "Duplicate advices", advices: [][]byte{other.publicKey, other.publicKey}, trusted: [][]byte{other.publicKey}, wantErr: true},
+		{name: "Wrong advice count", advices: [][]byte{producer.publicKey}, trusted: [][]byte{producer.publicKey}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := header.VerifyProducer(tt.advices, tt.trusted)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("VerifyProducer() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !bytes.Equal(got, producer.publicKey) {
+				t.Errorf("VerifyProducer() = %x, want %x", got, producer.publicKey)
+			}
+		})
+	}
+	got, err := header.VerifyProducerUntrusted(advices)
+	if err != nil || !bytes.Equal(got, producer.publicKey) {
+		t.Errorf("VerifyProducerUntrusted() = %x, error = %v", got, err)
+	}
+
+	header.Hash[0]++
+	if _, err := header.VerifyProducerUntrusted(advices); err == nil {
+		t.Errorf("VerifyProducerUntrusted() of changed hash must fail")
+	}
+}
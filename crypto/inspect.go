@@ -0,0 +1,163 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"github.com/btcsuite/btcutil/base58"
+)
+
+// Result of InspectTx, it describes the transaction as node sees it and lists problems, which make node reject it
+type TxInspection struct {
+	Hash              Hash               `json:"hash"`          // stored hash
+	ComputedHash      Hash               `json:"computed_hash"` // hash of content
+	Version           uint32             `json:"version"`
+	LockTime          uint32             `json:"lock_time"`
+	Size              int                `json:"size"`
+	Inputs            []InputInspection  `json:"inputs"`
+	Outputs           []OutputInspection `json:"outputs"`
+	TotalIn           Amount             `json:"total_in"`
+	TotalOut          Amount             `json:"total_out"`          // including commission output
+	Commission        Amount             `json:"commission"`         // value of commission output
+	ImpliedCommission Amount             `json:"implied_commission"` // total in without outputs except commission
+	Problems          []string           `json:"problems"`
+}
+
+// Input of inspected transaction
+type InputInspection struct {
+	PreviousOutput TransactionInputOutpoint `json:"previous_output"`
+	Sequence       uint32                   `json:"sequence"`
+	Amount         Amount                   `json:"amount"`
+	Address        string                   `json:"address"`     // wallet address of input
+	PublicKey      string                   `json:"public_key"`  // in hex
+	KeyAddress     string                   `json:"key_address"` // address of public key, it must be the same
+	SignatureValid bool                     `json:"signature_valid"`
+}
+
+// Output of inspected transaction
+type OutputInspection struct {
+	Index   uint32 `json:"index"`
+	Purpose string `json:"purpose"` // see OutputPurpose, change is an output to address of an input
+	Amount  Amount `json:"amount"`
+	Address string `json:"address,omitempty"`
+	NodeID  string `json:"node_id,omitempty"` // in hex, if it is set
+	Payload string `json:"payload,omitempty"` // in hex
+}
+
+// InspectTx decode fields of transaction, recompute its hash, check signatures and amounts. It doesn't fail on invalid
+// transactions, problems are listed in result instead
+func InspectTx(tx *Tx) *TxInspection {
+	ti := &TxInspection{
+		Hash:         tx.Hash,
+		ComputedHash: tx.GenerateHash(),
+		Version:      tx.Version,
+		LockTime:     tx.LockTime,
+		Size:         tx.Size(),
+		Inputs:       make([]InputInspection, 0, len(tx.Inputs)),
+		Outputs:      make([]OutputInspection, 0, len(tx.Outputs)),
+		Problems:     make([]string, 0),
+	}
+	if ti.Hash != ti.ComputedHash {
+		ti.problem("stored hash %s doesn't match computed hash %s", ti.Hash, ti.ComputedHash)
+	}
+
+	inputAddresses := make(map[string]bool)
+	for i, txIn := range tx.Inputs {
+		input := InputInspection{
+			PreviousOutput: txIn.PreviousOutput,
+			Sequence:       txIn.Sequence,
+			Amount:         Amount(txIn.PreviousOutput.Value),
+			Address:        base58.Encode(txIn.WalletAddress),
+			PublicKey:      hex.EncodeToString(txIn.PublicKey),
+		}
+		inputAddresses[input.Address] = true
+		if wallet, err := CreateWallet(txIn.PublicKey); err == nil {
+			input.KeyAddress = wallet.Base58Address
+		}
+		if input.KeyAddress != input.Address {
+			ti.problem("public key of input %d doesn't match address %s", i, input.Address)
+		}
+		input.SignatureValid = tx.verifyInput(i)
+		if !input.SignatureValid {
+			ti.problem("invalid signature of input %d", i)
+		}
+		if total, err := ti.TotalIn.Add(input.Amount); err != nil {
+			ti.problem("total of inputs overflows")
+		} else {
+			ti.TotalIn = total
+		}
+		ti.Inputs = append(ti.Inputs, input)
+	}
+
+	for i, txOut := range tx.Outputs {
+		output := OutputInspection{
+			Index:   txOut.Index,
+			Purpose: outputPurpose(i, txOut, inputAddresses).String(),
+			Amount:  txOut.Amount(),
+			Address: base58.Encode(txOut.Script),
+			Payload: hex.EncodeToString(txOut.Payload),
+		}
+		if !txOut.NodeID.IsEmpty() {
+			output.NodeID = hex.EncodeToString(txOut.NodeID[:])
+		}
+		if txOut.Index != uint32(i) {
+			ti.problem("output %d has index %d", i, txOut.Index)
+		}
+		if i > 0 && !IsWalletAddress(output.Address) {
+			ti.problem("invalid address of output %d", i)
+		}
+		if !bytes.Equal(txOut.WalletAddress, txOut.Script) && len(txOut.WalletAddress) > 0 {
+			ti.problem("wallet address of output %d doesn't match its script", i)
+		}
+		if total, err := ti.TotalOut.Add(output.Amount); err != nil {
+			ti.problem("total of outputs overflows")
+		} else {
+			ti.TotalOut = total
+		}
+		ti.Outputs = append(ti.Outputs, output)
+	}
+	if err := tx.CheckPayloads(); err != nil {
+		ti.problem("%v", err)
+	}
+
+	if len(tx.Outputs) == 0 || len(tx.Outputs[0].Script) > 0 || !tx.Outputs[0].NodeID.IsEmpty() {
+		ti.problem("the first output is not a commission output")
+	} else {
+		ti.Commission = tx.Outputs[0].Amount()
+	}
+	spent, _ := ti.TotalOut.Sub(ti.Commission)
+	implied, err := ti.TotalIn.Sub(spent)
+	if err != nil {
+		ti.problem("outputs exceed inputs by %s", spent-ti.TotalIn)
+	} else {
+		ti.ImpliedCommission = implied
+		if implied != ti.Commission {
+			ti.problem("implied commission %s differs from commission output %s", implied, ti.Commission)
+		}
+	}
+	return ti
+}
+
+// Valid check that the inspection has no problems
+func (ti *TxInspection) Valid() bool {
+	return len(ti.Problems) == 0
+}
+
+// problem add problem of transaction
+func (ti *TxInspection) problem(format string, args ...interface{}) {
+	ti.Problems = append(ti.Problems, fmt.Sprintf(format, args...))
+}
+
+// outputPurpose guess purpose of output. The first output without script is a commission, output to an address of
+// input is a change, output with NodeID is a stake
+func outputPurpose(i int, txOut TransactionOutput, inputAddresses map[string]bool) OutputPurpose {
+	switch {
+	case i == 0 && len(txOut.Script) == 0:
+		return PurposeCommission
+	case inputAddresses[base58.Encode(txOut.Script)]:
+		return PurposeChange
+	case !txOut.NodeID.IsEmpty():
+		return PurposeStake
+	}
+	return PurposePayment
+}
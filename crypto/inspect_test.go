@@ -0,0 +1,125 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"reflect"
+	"testing"
+)
+
+func TestInspectTx(t *testing.T) {
+	hd, _ := GenerateHD()
+	wallet, _ := hd.ToWallet()
+	unspents := []TransactionInputOutpoint{
+		{Hash: DHASH([]byte("first")), Index: 0, Value: 5000000},
+		{Hash: DHASH([]byte("second")), Index: 1, Value: 7000000},
+	}
+	nodeID := NodeID(DHASH([]byte("node")))
+	tests := []struct {
+		name         string
+		modify       func(tx *Tx)
+		wantPurposes []string
+		wantImplied  Amount
+		wantProblems int
+	}{
+		{
+			name:         "Correct",
+			modify:       func(tx *Tx) {},
+			wantPurposes: []string{"commission", "payment", "change"},
+			wantImplied:  1000000,
+			wantProblems: 0,
+		},
+		{
+			name: "Changed hash",
+			modify: func(tx *Tx) {
+				tx.Hash[0] ^= 0xff
+			},
+			wantPurposes: []string{"commission", "payment", "change"},
+			wantImplied:  1000000,
+			wantProblems: 1,
+		},
+		{
+			name: "Changed output",
+			modify: func(tx *Tx) {
+				tx.Outputs[2].Value -= 500
+				tx.Hash = tx.GenerateHash()
+			},
+			wantPurposes: []string{"commission", "payment", "change"},
+			wantImplied:  1000500,
+			wantProblems: 3,
+		},
+		{
+			name: "Outputs exceed inputs",
+			modify: func(tx *Tx) {
+				tx.Outputs[1].Value = 20000000
+				_ = tx.Sign(*hd)
+			},
+			wantPurposes: []string{"commission", "payment", "change"},
+			wantImplied:  0,
+			wantProblems: 1,
+		},
+		{
+			name: "Foreign public key",
+			modify: func(tx *Tx) {
+				other, _ := GenerateHD()
+				tx.Inputs[0].PublicKey = other.publicKey
+				tx.Hash = tx.GenerateHash()
+			},
+			wantPurposes: []string{"commission", "payment", "change"},
+			wantImplied:  1000000,
+			wantProblems: 2,
+		},
+		{
+			name: "Without commission output",
+			modify: func(tx *Tx) {
+				tx.Outputs = tx.Outputs[1:]
+				_ = tx.Sign(*hd)
+			},
+			wantPurposes: []string{"payment", "change"},
+			wantImplied:  1000000,
+			wantProblems: 4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx, err := NewTransaction(unspents, 1000, *hd, wallet.Base58Address, "VLa1hi77ZXD2BSWDD9wQe8vAhejXyS7vBM4", 1000000, nodeID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.modify(tx)
+			got := InspectTx(tx)
+			if len(got.Problems) != tt.wantProblems {
+				t.Errorf("InspectTx() problems = %q, want %d", got.Problems, tt.wantProblems)
+			}
+			if got.Valid() != (tt.wantProblems == 0) {
+				t.Errorf("Valid() = %v", got.Valid())
+			}
+			purposes := make([]string, 0)
+			for _, output := range got.Outputs {
+				purposes = append(purposes, output.Purpose)
+			}
+			if !reflect.DeepEqual(purposes, tt.wantPurposes) {
+				t.Errorf("InspectTx() purposes = %v, want %v", purposes, tt.wantPurposes)
+			}
+			if got.ImpliedCommission != tt.wantImplied {
+				t.Errorf("InspectTx() implied commission = %d, want %d", got.ImpliedCommission, tt.wantImplied)
+			}
+			if got.TotalIn != 12000000 || got.ComputedHash != tx.GenerateHash() || got.Hash != tx.Hash {
+				t.Errorf("InspectTx() got = %+v", got)
+			}
+		})
+	}
+
+	tx, _ := NewTransaction(unspents, 1000, *hd, wallet.Base58Address, "VLa1hi77ZXD2BSWDD9wQe8vAhejXyS7vBM4", 1000000, nodeID)
+	got := InspectTx(tx)
+	input := got.Inputs[0]
+	if input.Address != wallet.Base58Address || input.KeyAddress != wallet.Base58Address || !input.SignatureValid {
+		t.Errorf("InspectTx() input = %+v", input)
+	}
+	change := got.Outputs[2]
+	if change.Address != wallet.Base58Address || change.NodeID != hex.EncodeToString(nodeID[:]) {
+		t.Errorf("InspectTx() change = %+v", change)
+	}
+	if got.Size != tx.Size() || got.TotalOut != got.TotalIn {
+		t.Errorf("InspectTx() got = %+v", got)
+	}
+}
@@ -0,0 +1,89 @@
+package crypto
+
+import (
+	"bytes"
+	"github.com/GoKillers/libsodium-go/cryptosign"
+	"github.com/go-errors/errors"
+	"time"
+)
+
+// LockTime less than threshold is a block height, else it is a unix timestamp. The threshold and finality rules of
+// IsFinal follow Bitcoin nLockTime and nSequence, they are not confirmed against the node implementation, so the
+// node can treat locked transactions differently
+const LockTimeThreshold = 500000000
+
+// Sequence used by transaction builders
+const DefaultSequence = 1
+
+// Sequence of input, which disables LockTime, when all inputs have it
+const SequenceFinal = 0xffffffff
+
+// LockAtHeight return LockTime, which allows transaction in blocks from height
+func LockAtHeight(height uint32) (uint32, error) {
+	if height >= LockTimeThreshold {
+		return 0, errors.Errorf("Lock height %d must be less than %d", height, LockTimeThreshold)
+	}
+	return height, nil
+}
+
+// LockAtTime return LockTime, which allows transaction in blocks with timestamp from t
+func LockAtTime(t time.Time) (uint32, error) {
+	unix := t.Unix()
+	if unix < LockTimeThreshold || unix > int64(^uint32(0)) {
+		return 0, errors.Errorf("Lock time %s is out of range", t.UTC().Format(time.RFC3339))
+	}
+	return uint32(unix), nil
+}
+
+// IsHeightLock check that LockTime is a block height
+func (tx *Tx) IsHeightLock() bool {
+	return tx.LockTime < LockTimeThreshold
+}
+
+// IsFinal check that transaction can be included into block with height and timestamp. Transaction is final, if
+// LockTime is zero, LockTime is not greater than height or timestamp, or all inputs have SequenceFinal. The result is
+// advisory only: rules are taken from Bitcoin and node can reject a transaction, which IsFinal accepts, or accept
+// a transaction, which it rejects
+func (tx *Tx) IsFinal(height uint32, timestamp uint32) bool {
+	if tx.LockTime == 0 {
+		return true
+	}
+	if tx.IsHeightLock() && tx.LockTime <= height {
+		return true
+	}
+	if !tx.IsHeightLock() && tx.LockTime <= timestamp {
+		return true
+	}
+	for _, txIn := range tx.Inputs {
+		if txIn.Sequence != SequenceFinal {
+			return false
+		}
+	}
+	return true
+}
+
+// Sign sign every input with the key of its public key and update hash. It is used for transactions built without
+// keys, builders sign transactions themselves
+func (tx *Tx) Sign(keys ...HD) error {
+	for i := range tx.Inputs {
+		txIn := &tx.Inputs[i]
+		var key *HD
+		for k := range keys {
+			if bytes.Equal(keys[k].publicKey, txIn.PublicKey) {
+				key = &keys[k]
+				break
+			}
+		}
+		if key == nil {
+			return errors.Errorf("Key for input %d is not found", i)
+		}
+		sigMsg := tx.msgForSign(txIn.PreviousOutput.Hash, txIn.PreviousOutput.Index)
+		sig, err := cryptosign.CryptoSignDetached(sigMsg, key.privateKey)
+		if err != 0 {
+			return errors.Errorf("Error on sign message")
+		}
+		txIn.Script = sig
+	}
+	tx.Hash = tx.GenerateHash()
+	return nil
+}
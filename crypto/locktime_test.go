@@ -0,0 +1,80 @@
+package crypto
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTx_IsFinal(t *testing.T) {
+	timeLock, _ := LockAtTime(time.Unix(1600000000, 0))
+	tests := []struct {
+		name      string
+		lockTime  uint32
+		sequence  uint32
+		height    uint32
+		timestamp uint32
+		want      bool
+	}{
+		{name: "Without lock", lockTime: 0, sequence: DefaultSequence, height: 1, want: true},
+		{name: "Height is not reached", lockTime: 100, sequence: DefaultSequence, height: 99, want: false},
+		{name: "Height is reached", lockTime: 100, sequence: DefaultSequence, height: 100, want: true},
+		{name: "Time is not reached", lockTime: timeLock, sequence: DefaultSequence, height: 2000, timestamp: 1599999999, want: false},
+		{name: "Time is reached", lockTime: timeLock, sequence: DefaultSequence, height: 1, timestamp: 1600000000, want: true},
+		{name: "Final sequence", lockTime: 100, sequence: SequenceFinal, height: 1, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := Tx{
+				LockTime: tt.lockTime,
+				Inputs:   []TransactionInput{{Sequence: tt.sequence}, {Sequence: tt.sequence}},
+			}
+			if got := tx.IsFinal(tt.height, tt.timestamp); got != tt.want {
+				t.Errorf("IsFinal() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewTransactionWithOptions_LockTime(t *testing.T) {
+	hd, _ := GenerateHD()
+	wallet, _ := hd.ToWallet()
+	unspents := []TransactionInputOutpoint{
+		{Hash: DHASH([]byte("first")), Index: 0, Value: 5000000},
+		{Hash: DHASH([]byte("second")), Index: 1, Value: 5000000},
+	}
+	receivers := []Receiver{{Wallet: "VLa1hi77ZXD2BSWDD9wQe8vAhejXyS7vBM4", Amount: 1000}}
+	lockTime, _ := LockAtHeight(150)
+	opts := TxOptions{LockTime: lockTime, Sequences: []uint32{SequenceFinal}}
+
+	tx, err := NewTransactionWithOptions(unspents, *hd, wallet.Base58Address, receivers, 1000000, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Verify(); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+	if tx.LockTime != 150 || !tx.IsHeightLock() {
+		t.Errorf("LockTime = %d, want height 150", tx.LockTime)
+	}
+	if tx.Inputs[0].Sequence != SequenceFinal || tx.Inputs[1].Sequence != DefaultSequence {
+		t.Errorf("Sequences = %d, %d, want %d, %d", tx.Inputs[0].Sequence, tx.Inputs[1].Sequence, SequenceFinal, DefaultSequence)
+	}
+	// lock time is signed
+	changed := *tx
+	changed.LockTime = 0
+	changed.Hash = changed.GenerateHash()
+	if err := changed.Verify(); err == nil {
+		t.Errorf("Verify() of transaction with changed lock time must fail")
+	}
+
+	opts.Sequences = []uint32{1, 2, 3}
+	if _, err := NewTransactionWithOptions(unspents, *hd, wallet.Base58Address, receivers, 1000000, opts); err == nil {
+		t.Errorf("NewTransactionWithOptions() with more sequences than inputs must fail")
+	}
+	if _, err := LockAtHeight(LockTimeThreshold); err == nil {
+		t.Errorf("LockAtHeight() over threshold must fail")
+	}
+	if _, err := LockAtTime(time.Unix(1000, 0)); err == nil {
+		t.Errorf("LockAtTime() under threshold must fail")
+	}
+}
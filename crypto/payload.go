@@ -0,0 +1,84 @@
+package crypto
+
+import (
+	"encoding/json"
+	"github.com/go-errors/errors"
+	"unicode/utf8"
+)
+
+// Maximum length of output payload in bytes. Payload is included into hash and signature of output with its length, only
+// if it is not empty, so transactions without payload keep their hashes
+const MaxPayloadSize = 256
+
+// TextPayload convert utf-8 text to payload, for example invoice id
+func TextPayload(text string) ([]byte, error) {
+	if !utf8.ValidString(text) {
+		return nil, errors.Errorf("Payload text is not valid utf-8")
+	}
+	payload := []byte(text)
+	if err := checkPayload(payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// JSONPayload encode value to json payload
+func JSONPayload(v interface{}) ([]byte, error) {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return nil, errors.New(err)
+	}
+	if err := checkPayload(payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// SetPayload attach payload to output, commission output can't have payload
+func (to *TransactionOutput) SetPayload(payload []byte) error {
+	if len(to.Script) == 0 {
+		return errors.Errorf("Commission output can't have payload")
+	}
+	if err := checkPayload(payload); err != nil {
+		return err
+	}
+	to.Payload = payload
+	return nil
+}
+
+// PayloadText return payload as text, error if it is not valid utf-8
+func (to *TransactionOutput) PayloadText() (string, error) {
+	if !utf8.Valid(to.Payload) {
+		return "", errors.Errorf("Payload is not valid utf-8")
+	}
+	return string(to.Payload), nil
+}
+
+// DecodePayloadJSON decode json payload to v
+func (to *TransactionOutput) DecodePayloadJSON(v interface{}) error {
+	if err := json.Unmarshal(to.Payload, v); err != nil {
+		return errors.New(err)
+	}
+	return nil
+}
+
+// CheckPayloads check size of payloads of all outputs
+func (tx *Tx) CheckPayloads() error {
+	for _, txOut := range tx.Outputs {
+		if len(txOut.Payload) > 0 && len(txOut.Script) == 0 {
+			return errors.Errorf("Commission output %d has payload", txOut.Index)
+		}
+		if err := checkPayload(txOut.Payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkPayload check payload size
+func checkPayload(payload []byte) error {
+	if len(payload) > MaxPayloadSize {
+		return errors.Errorf("Payload size %d is greater than %d bytes", len(payload), MaxPayloadSize)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestTextPayload(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		wantErr bool
+	}{
+		{name: "Invoice id", text: "invoice-2019-0042", wantErr: false},
+		{name: "Maximum size", text: strings.Repeat("a", MaxPayloadSize), wantErr: false},
+		{name: "Too long", text: strings.Repeat("a", MaxPayloadSize+1), wantErr: true},
+		{name: "Invalid utf-8", text: string([]byte{0xff, 0xfe}), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := TextPayload(tt.text)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("TextPayload() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && string(got) != tt.text {
+				t.Errorf("TextPayload() = %s, want %s", got, tt.text)
+			}
+		})
+	}
+}
+
+func TestTx_Payload(t *testing.T) {
+	hd, _ := GenerateHD()
+	wallet, _ := hd.ToWallet()
+	unspents := []TransactionInputOutpoint{{Hash: DHASH([]byte("first")), Index: 0, Value: 5000000}}
+	type invoice struct {
+		ID     string `json:"id"`
+		Amount uint64 `json:"amount"`
+	}
+	payload, err := JSONPayload(invoice{ID: "42", Amount: 1000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	receivers := []Receiver{{Wallet: "VLa1hi77ZXD2BSWDD9wQe8vAhejXyS7vBM4", Amount: 1000, Payload: payload}}
+	tx, err := NewTransactionManyRecievers(unspents, *hd, wallet.Base58Address, receivers, 1000000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Verify(); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if err := tx.CheckPayloads(); err != nil {
+		t.Errorf("CheckPayloads() error = %v", err)
+	}
+	got := invoice{}
+	if err := tx.Outputs[1].DecodePayloadJSON(&got); err != nil || got.ID != "42" {
+		t.Errorf("DecodePayloadJSON() = %+v, error = %v", got, err)
+	}
+
+	// payload is signed and hashed
+	msg := tx.Outputs[1].msgForSign()
+	if !bytes.HasSuffix(msg, payload) {
+		t.Errorf("msgForSign() doesn't end with payload")
+	}
+	changed := *tx
+	changed.Outputs = append([]TransactionOutput{}, tx.Outputs...)
+	changed.Outputs[1].Payload = []byte(`{"id":"43","amount":1000}`)
+	if changed.Verify() == nil {
+		t.Errorf("Verify() of transaction with changed payload must fail")
+	}
+
+	// payload survives json
+	data, err := json.Marshal(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := Tx{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decoded.Outputs[1].Payload, payload) || decoded.Outputs[0].Payload != nil {
+		t.Errorf("payloads after json = %x, %x", decoded.Outputs[0].Payload, decoded.Outputs[1].Payload)
+	}
+	if decoded.GenerateHash() != tx.Hash {
+		t.Errorf("hash of decoded transaction doesn't match")
+	}
+	// payload keeps default encoding of bytes, base64 or null
+	outputs := struct {
+		Outputs []struct {
+			Payload *string `json:"payload"`
+		} `json:"tx_out"`
+	}{}
+	if err := json.Unmarshal(data, &outputs); err != nil {
+		t.Fatal(err)
+	}
+	if outputs.Outputs[0].Payload != nil || outputs.Outputs[1].Payload == nil ||
+		*outputs.Outputs[1].Payload != base64.StdEncoding.EncodeToString(payload) {
+		t.Errorf("payloads in json = %v, %v", outputs.Outputs[0].Payload, outputs.Outputs[1].Payload)
+	}
+
+	// output with NodeID isn't signed like output with payload of the same bytes
+	nodeID := NodeID(DHASH([]byte("node")))
+	staked := TransactionOutput{Index: 1, Value: 1000, Script: tx.Outputs[1].Script, NodeID: nodeID}
+	withPayload := TransactionOutput{Index: 1, Value: 1000, Script: tx.Outputs[1].Script, Payload: nodeID[:]}
+	if bytes.Equal(staked.msgForSign(), withPayload.msgForSign()) || bytes.Equal(staked.forBlkHash(), withPayload.forBlkHash()) {
+		t.Errorf("output with NodeID and output with payload %x have the same bytes", nodeID)
+	}
+
+	commission := tx.Outputs[0]
+	if err := commission.SetPayload([]byte("memo")); err == nil {
+		t.Errorf("SetPayload() of commission output must fail")
+	}
+}
@@ -0,0 +1,173 @@
+// Package staking creates transactions for staking to validator nodes. Stake is an output of own wallet with NodeID of
+// validator, node returns such outputs only in unspent_for_staking list.
+package staking
+
+import (
+	"bytes"
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/go-errors/errors"
+	"github.com/velas/GoVelas/crypto"
+)
+
+// Error of operation with stake without validator NodeID
+var ErrEmptyNodeID = errors.New("Node ID of validator is empty")
+
+// Error of operation with stakes, which don't belong to the key
+var ErrNotOwner = errors.New("Stake doesn't belong to the key")
+
+// Active stake of wallet
+type Stake struct {
+	Outpoint  crypto.TransactionInputOutpoint
+	Address   string        // wallet address, which owns the stake
+	Validator crypto.NodeID // node id of validator
+}
+
+// NewStake create transaction, which stakes amount from regular unspents of key to validator, change is returned to
+// wallet without NodeID
+func NewStake(
+	unspents []crypto.TransactionInputOutpoint,
+	key crypto.HD,
+	validator crypto.NodeID,
+	amount crypto.Amount,
+	commission crypto.Amount,
+) (*crypto.Tx, error) {
+	if validator.IsEmpty() {
+		return nil, ErrEmptyNodeID
+	}
+	if amount == 0 {
+		return nil, errors.Errorf("Stake amount must be positive")
+	}
+	wallet, err := key.ToWallet()
+	if err != nil {
+		return nil, err
+	}
+	receivers := []crypto.Receiver{{Wallet: wallet.Base58Address, Amount: amount, NodeID: validator}}
+	return crypto.NewTransactionManyRecievers(unspents, key, wallet.Base58Address, receivers, commission)
+}
+
+// Split divide stake into stakes with amounts to the same validator, the rest without commission becomes one more stake
+func Split(stake Stake, key crypto.HD, amounts []crypto.Amount, commission crypto.Amount) (*crypto.Tx, error) {
+	if err := checkStakes([]Stake{stake}, key); err != nil {
+		return nil, err
+	}
+	spent := commission
+	receivers := make([]crypto.Receiver, 0, len(amounts)+1)
+	for _, amount := range amounts {
+		if amount == 0 {
+			return nil, errors.Errorf("Stake amount must be positive")
+		}
+		var err error
+		if spent, err = spent.Add(amount); err != nil {
+			return nil, err
+		}
+		receivers = append(receivers, crypto.Receiver{Wallet: stake.Address, Amount: amount, NodeID: stake.Validator})
+	}
+	rest, err := crypto.Amount(stake.Outpoint.Value).Sub(spent)
+	if err != nil {
+		return nil, errors.Errorf("Insufficient stake, stake amount %d, commission %d, split amount %d",
+			stake.Outpoint.Value, commission, spent-commission)
+	}
+	if rest > 0 {
+		receivers = append(receivers, crypto.Receiver{Wallet: stake.Address, Amount: rest, NodeID: stake.Validator})
+	}
+	return crypto.NewTransactionManyRecievers(
+		[]crypto.TransactionInputOutpoint{stake.Outpoint}, key, stake.Address, receivers, commission)
+}
+
+// Merge join stakes to the same validator into one stake
+func Merge(stakes []Stake, key crypto.HD, commission crypto.Amount) (*crypto.Tx, error) {
+	if len(stakes) < 2 {
+		return nil, errors.Errorf("At least two stakes are required for merge")
+	}
+	if err := checkStakes(stakes, key); err != nil {
+		return nil, err
+	}
+	for _, stake := range stakes[1:] {
+		if stake.Validator != stakes[0].Validator {
+			return nil, errors.Errorf("Stakes to different validators can't be merged")
+		}
+	}
+	total, unspents, err := outpoints(stakes)
+	if err != nil {
+		return nil, err
+	}
+	if commission >= total {
+		return nil, errors.Errorf("Insufficient stake, stake amount %d, commission %d", total, commission)
+	}
+	receivers := []crypto.Receiver{
+		{Wallet: stakes[0].Address, Amount: total - commission, NodeID: stakes[0].Validator},
+	}
+	return crypto.NewTransactionManyRecievers(unspents, key, stakes[0].Address, receivers, commission)
+}
+
+// Withdraw return stakes to wallet as one regular output without NodeID
+func Withdraw(stakes []Stake, key crypto.HD, commission crypto.Amount) (*crypto.Tx, error) {
+	if len(stakes) == 0 {
+		return nil, errors.Errorf("No stakes for withdraw")
+	}
+	if err := checkStakes(stakes, key); err != nil {
+		return nil, err
+	}
+	total, unspents, err := outpoints(stakes)
+	if err != nil {
+		return nil, err
+	}
+	if commission >= total {
+		return nil, errors.Errorf("Insufficient stake, stake amount %d, commission %d", total, commission)
+	}
+	receivers := []crypto.Receiver{{Wallet: stakes[0].Address, Amount: total - commission}}
+	return crypto.NewTransactionManyRecievers(unspents, key, stakes[0].Address, receivers, commission)
+}
+
+// FindStakes return stakes of address among unspent_for_staking outputs, txs must contain transactions of outputs.
+// Outputs without NodeID or with unknown transaction are skipped
+func FindStakes(address string, unspents []crypto.TransactionInputOutpoint, txs []crypto.Tx) []Stake {
+	byHash := make(map[crypto.Hash]*crypto.Tx, len(txs))
+	for i := range txs {
+		byHash[txs[i].Hash] = &txs[i]
+	}
+	script := base58.Decode(address)
+	stakes := make([]Stake, 0)
+	for _, unspent := range unspents {
+		tx, ok := byHash[unspent.Hash]
+		if !ok || int(unspent.Index) >= len(tx.Outputs) {
+			continue
+		}
+		output := tx.Outputs[unspent.Index]
+		if output.NodeID.IsEmpty() || !bytes.Equal(output.Script, script) {
+			continue
+		}
+		stakes = append(stakes, Stake{Outpoint: unspent, Address: address, Validator: output.NodeID})
+	}
+	return stakes
+}
+
+// checkStakes check that stakes have validator and belong to wallet of key
+func checkStakes(stakes []Stake, key crypto.HD) error {
+	wallet, err := key.ToWallet()
+	if err != nil {
+		return err
+	}
+	for _, stake := range stakes {
+		if stake.Validator.IsEmpty() {
+			return ErrEmptyNodeID
+		}
+		if stake.Address != wallet.Base58Address {
+			return ErrNotOwner
+		}
+	}
+	return nil
+}
+
+// outpoints return total amount and outpoints of stakes
+func outpoints(stakes []Stake) (crypto.Amount, []crypto.TransactionInputOutpoint, error) {
+	result := make([]crypto.TransactionInputOutpoint, 0, len(stakes))
+	for _, stake := range stakes {
+		result = append(result, stake.Outpoint)
+	}
+	total, err := crypto.SumUnspents(result)
+	if err != nil {
+		return 0, nil, err
+	}
+	return total, result, nil
+}
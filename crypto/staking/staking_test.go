@@ -0,0 +1,148 @@
+package staking
+
+import (
+	"github.com/velas/GoVelas/crypto"
+	"testing"
+)
+
+func TestNewStake(t *testing.T) {
+	hd, _ := crypto.GenerateHD()
+	unspents := []crypto.TransactionInputOutpoint{{Hash: crypto.DHASH([]byte("first")), Index: 1, Value: 10000000}}
+	validator := crypto.NodeID{1, 2, 3}
+
+	tx, err := NewStake(unspents, *hd, validator, 4000000, 1000000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tx.Outputs) != 3 {
+		t.Fatalf("NewStake() got %d outputs, want 3", len(tx.Outputs))
+	}
+	if tx.Outputs[1].NodeID != validator || tx.Outputs[1].Value != 4000000 {
+		t.Errorf("stake output = %+v", tx.Outputs[1])
+	}
+	if !tx.Outputs[2].NodeID.IsEmpty() || tx.Outputs[2].Value != 5000000 {
+		t.Errorf("change output = %+v", tx.Outputs[2])
+	}
+
+	if _, err := NewStake(unspents, *hd, crypto.NodeID{}, 4000000, 1000000); err != ErrEmptyNodeID {
+		t.Errorf("NewStake() without validator error = %v, want %v", err, ErrEmptyNodeID)
+	}
+}
+
+func TestStakeOperations(t *testing.T) {
+	hd, _ := crypto.GenerateHD()
+	wallet, _ := hd.ToWallet()
+	other, _ := crypto.GenerateHD()
+	validator := crypto.NodeID{1}
+	stakes := []Stake{
+		{
+			Outpoint:  crypto.TransactionInputOutpoint{Hash: crypto.DHASH([]byte("first")), Index: 1, Value: 10000000},
+			Address:   wallet.Base58Address,
+			Validator: validator,
+		},
+		{
+			Outpoint:  crypto.TransactionInputOutpoint{Hash: crypto.DHASH([]byte("second")), Index: 2, Value: 6000000},
+			Address:   wallet.Base58Address,
+			Validator: validator,
+		},
+	}
+
+	tests := []struct {
+		name       string
+		build      func() (*crypto.Tx, error)
+		wantValues []uint64
+		wantStake  []bool
+		wantErr    bool
+		errIs      error // expected sentinel error
+	}{
+		{
+			name:       "Split",
+			build:      func() (*crypto.Tx, error) { return Split(stakes[0], *hd, []crypto.Amount{3000000, 2000000}, 1000000) },
+			wantValues: []uint64{1000000, 3000000, 2000000, 4000000},
+			wantStake:  []bool{false, true, true, true},
+		},
+		{
+			name:       "Merge",
+			build:      func() (*crypto.Tx, error) { return Merge(stakes, *hd, 1000000) },
+			wantValues: []uint64{1000000, 15000000},
+			wantStake:  []bool{false, true},
+		},
+		{
+			name:       "Withdraw",
+			build:      func() (*crypto.Tx, error) { return Withdraw(stakes, *hd, 1000000) },
+			wantValues: []uint64{1000000, 15000000},
+			wantStake:  []bool{false, false},
+		},
+		{
+			name: "Merge different validators",
+			build: func() (*crypto.Tx, error) {
+				second := stakes[1]
+				second.Validator = crypto.NodeID{2}
+				return Merge([]Stake{stakes[0], second}, *hd, 1000000)
+			},
+			wantErr: true,
+		},
+		{
+			name:    "Withdraw of another wallet",
+			build:   func() (*crypto.Tx, error) { return Withdraw(stakes, *other, 1000000) },
+			wantErr: true,
+			errIs:   ErrNotOwner,
+		},
+		{
+			name: "Split without validator",
+			build: func() (*crypto.Tx, error) {
+				stake := stakes[0]
+				stake.Validator = crypto.NodeID{}
+				return Split(stake, *hd, []crypto.Amount{1000000}, 1000000)
+			},
+			wantErr: true,
+			errIs:   ErrEmptyNodeID,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx, err := tt.build()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if tt.errIs != nil && err != tt.errIs {
+					t.Errorf("error = %v, want %v", err, tt.errIs)
+				}
+				return
+			}
+			if err := tx.Verify(); err != nil {
+				t.Errorf("Verify() error = %v", err)
+			}
+			if len(tx.Outputs) != len(tt.wantValues) {
+				t.Fatalf("got %d outputs, want %d", len(tx.Outputs), len(tt.wantValues))
+			}
+			for i, output := range tx.Outputs {
+				if output.Value != tt.wantValues[i] || output.NodeID.IsEmpty() == tt.wantStake[i] {
+					t.Errorf("output %d value = %d, stake = %v, want %d and %v",
+						i, output.Value, !output.NodeID.IsEmpty(), tt.wantValues[i], tt.wantStake[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFindStakes(t *testing.T) {
+	hd, _ := crypto.GenerateHD()
+	wallet, _ := hd.ToWallet()
+	unspents := []crypto.TransactionInputOutpoint{{Hash: crypto.DHASH([]byte("first")), Index: 0, Value: 10000000}}
+	tx, _ := NewStake(unspents, *hd, crypto.NodeID{7}, 4000000, 1000000)
+
+	forStaking := []crypto.TransactionInputOutpoint{
+		{Hash: tx.Hash, Index: 1, Value: 4000000},
+		{Hash: tx.Hash, Index: 2, Value: 5000000},
+		{Hash: crypto.DHASH([]byte("unknown")), Index: 0, Value: 1},
+	}
+	stakes := FindStakes(wallet.Base58Address, forStaking, []crypto.Tx{*tx})
+	if len(stakes) != 1 {
+		t.Fatalf("FindStakes() got %d stakes, want 1", len(stakes))
+	}
+	if stakes[0].Outpoint != forStaking[0] || stakes[0].Validator != (crypto.NodeID{7}) {
+		t.Errorf("FindStakes() got %+v", stakes[0])
+	}
+}
@@ -0,0 +1,88 @@
+package crypto
+
+import (
+	"github.com/go-errors/errors"
+)
+
+// Unspent outputs of one key, spent by sweep transaction
+type SweepSource struct {
+	Key      HD
+	Unspents []TransactionInputOutpoint
+}
+
+// NewSweepTransaction create transaction, which spends all unspents of sources to one receiver. Commission is
+// calculated with rates and deducted from the sent amount, transaction doesn't have change output. Every input is
+// signed by the key of its source
+func NewSweepTransaction(sources []SweepSource, to string, rates FeeRates) (*Tx, error) {
+	total, commission, err := sweepCommission(sources, to, rates)
+	if err != nil {
+		return nil, err
+	}
+	return newSweepTransaction(sources, to, total-commission, commission)
+}
+
+// sweepCommission return total value of sources and commission of sweep transaction, commission is less than total
+func sweepCommission(sources []SweepSource, to string, rates FeeRates) (Amount, Amount, error) {
+	if !IsWalletAddress(to) {
+		return 0, 0, errors.Errorf("Invalid receiver address %s", to)
+	}
+	total := Amount(0)
+	inputs := 0
+	for _, source := range sources {
+		sum, err := SumUnspents(source.Unspents)
+		if err != nil {
+			return 0, 0, err
+		}
+		if total, err = total.Add(sum); err != nil {
+			return 0, 0, err
+		}
+		inputs += len(source.Unspents)
+	}
+	if inputs == 0 {
+		return 0, 0, errors.Errorf("No unspent outputs")
+	}
+
+	// values of outputs have fixed length, so size of draft is equal to size of the final transaction
+	draft, err := newSweepTransaction(sources, to, total, 0)
+	if err != nil {
+		return 0, 0, err
+	}
+	commission, err := rates.ForTx(draft)
+	if err != nil {
+		return 0, 0, err
+	}
+	if commission >= total {
+		return 0, 0, errors.Errorf("Insufficient funds, total amount %d, commission %d", total, commission)
+	}
+	return total, commission, nil
+}
+
+// newSweepTransaction create and sign transaction with commission and receiver outputs
+func newSweepTransaction(sources []SweepSource, to string, amount Amount, commission Amount) (*Tx, error) {
+	commissionOut, err := NewOutput(PurposeCommission, 0, "", commission, NodeID{})
+	if err != nil {
+		return nil, err
+	}
+	paymentOut, err := NewOutput(PurposePayment, 1, to, amount, NodeID{})
+	if err != nil {
+		return nil, err
+	}
+	tx := Tx{
+		Version:  1,
+		LockTime: 0,
+		Inputs:   make([]TransactionInput, 0),
+		Outputs:  []TransactionOutput{commissionOut, paymentOut},
+	}
+
+	for _, source := range sources {
+		wallet, err := source.Key.ToWallet()
+		if err != nil {
+			return nil, err
+		}
+		if err := tx.addSignedInputs(source.Unspents, source.Key, wallet.Base58Address); err != nil {
+			return nil, err
+		}
+	}
+	tx.Hash = tx.GenerateHash()
+	return &tx, nil
+}
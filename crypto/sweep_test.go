@@ -0,0 +1,53 @@
+package crypto
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestNewSweepTransaction(t *testing.T) {
+	first, _ := GenerateHD()
+	second, _ := GenerateHD()
+	to := "VLa1hi77ZXD2BSWDD9wQe8vAhejXyS7vBM4"
+	sources := []SweepSource{
+		{Key: *first, Unspents: []TransactionInputOutpoint{
+			{Hash: DHASH([]byte("first")), Index: 0, Value: 5000000},
+			{Hash: DHASH([]byte("second")), Index: 2, Value: 7000000},
+		}},
+		{Key: *second, Unspents: []TransactionInputOutpoint{
+			{Hash: DHASH([]byte("third")), Index: 1, Value: 3000000},
+		}},
+	}
+	rates := FeeRates{Base: 1000, PerByte: 10, PerInput: 100}
+
+	tx, err := NewSweepTransaction(sources, to, rates)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Verify(); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+	if len(tx.Inputs) != 3 || len(tx.Outputs) != 2 {
+		t.Fatalf("got %d inputs and %d outputs, want 3 inputs and 2 outputs", len(tx.Inputs), len(tx.Outputs))
+	}
+	commission, _ := rates.ForTx(tx)
+	if tx.Outputs[0].Amount() != commission {
+		t.Errorf("commission = %d, want %d", tx.Outputs[0].Value, commission)
+	}
+	if tx.Outputs[1].Amount() != 15000000-commission {
+		t.Errorf("amount = %d, want %d", tx.Outputs[1].Value, 15000000-commission)
+	}
+	if !bytes.Equal(tx.Inputs[2].PublicKey, second.publicKey) {
+		t.Errorf("input of second source is not signed by second key")
+	}
+
+	if _, err := NewSweepTransaction(nil, to, rates); err == nil {
+		t.Errorf("NewSweepTransaction() without unspents must fail")
+	}
+	if _, err := NewSweepTransaction(sources, to, FeeRates{Min: 15000000}); err == nil {
+		t.Errorf("NewSweepTransaction() with commission greater than funds must fail")
+	}
+	if _, err := NewSweepTransaction(sources, "invalid", rates); err == nil {
+		t.Errorf("NewSweepTransaction() to invalid address must fail")
+	}
+}
@@ -2,8 +2,6 @@ package crypto
 
 import (
 	"crypto/sha256"
-	"encoding/hex"
-	"encoding/json"
 	"github.com/GoKillers/libsodium-go/cryptosign"
 	"github.com/btcsuite/btcutil/base58"
 	"github.com/go-errors/errors"
@@ -12,7 +10,7 @@ import (
 
 // Transaction object
 type Tx struct {
-	Hash     [32]byte            `json:"hash"`
+	Hash     Hash                `json:"hash"`
 	Version  uint32              `json:"version"`
 	LockTime uint32              `json:"lock_time"`
 	Inputs   []TransactionInput  `json:"tx_in"`
@@ -21,173 +19,160 @@ type Tx struct {
 
 // To create a transaction, it is necessary to receive unspent outputs of the sender’s wallet, all outs will be spent on
 // commission, to the recipient and the remaining amount will be returned to the sender. Sign method generate hash of
-// transaction.
+// transaction. Recipient output is a payment without NodeID, nodeID is the NodeID of change output, see TxOptions.
+// Payload of recipient output is set with Receiver.Payload, see NewTransactionWithOptions
 func NewTransaction(
 	unspents []TransactionInputOutpoint,
-	amount uint64,
+	amount Amount,
 	key HD,
 	fromAddress string,
 	to string,
-	commission uint64,
+	commission Amount,
 	nodeID NodeID,
 ) (*Tx, error) {
-	totalin := int64(0)
-
-	for _, previousOutput := range unspents {
-		totalin += int64(previousOutput.Value)
-	}
-
-	index := uint32(0)
-
-	txIns := make([]TransactionInput, 0)
-
-	txOuts := make([]TransactionOutput, 0)
-
-	txOuts = append(txOuts, TransactionOutput{
-		Index: index,
-		Value: commission,
-	})
-
-	index++
-	txOuts = append(txOuts, TransactionOutput{
-		Index:         index,
-		Script:        base58.Decode(to),
-		Value:         amount,
-		WalletAddress: base58.Decode(to),
-		// NodeID:        nodeID,
-	})
-
-	change := totalin - int64(amount) - int64(commission)
+	receivers := []Receiver{{Wallet: to, Amount: amount}}
+	return NewTransactionWithOptions(unspents, key, fromAddress, receivers, commission, TxOptions{ChangeNodeID: nodeID})
+}
 
-	if change < 0 {
-		return nil, errors.Errorf("Insufficient funds, total amount %d, commission %d, send amount %d", totalin, commission, amount)
-	} else if change > 0 {
-		// My address
-		index++
-		txOuts = append(txOuts, TransactionOutput{
-			Index:         index,
-			Script:        base58.Decode(fromAddress),
-			Value:         uint64(change),
-			WalletAddress: base58.Decode(fromAddress),
-			NodeID:        nodeID,
-		})
-	}
+// Receiver of transaction with many receivers, output is a stake if NodeID is set, else a payment
+type Receiver struct {
+	Wallet  string
+	Amount  Amount
+	NodeID  NodeID
+	Payload []byte // optional memo, see TextPayload and JSONPayload
+}
 
-	tx := Tx{
-		Version:  1,
-		LockTime: 0,
-		Outputs:  txOuts,
+// Purpose return purpose of receiver output
+func (r Receiver) Purpose() OutputPurpose {
+	if r.NodeID.IsEmpty() {
+		return PurposePayment
 	}
+	return PurposeStake
+}
 
-	for _, previousOutput := range unspents {
-		sigMsg := tx.msgForSign(previousOutput.Hash, previousOutput.Index)
-		sig, err := cryptosign.CryptoSignDetached(sigMsg, key.privateKey)
-		if err != 0 {
-			return nil, errors.Errorf("Error on sign message")
-		}
-		txIns = append(txIns, TransactionInput{
-			PublicKey:      key.publicKey,
-			Sequence:       1,
-			PreviousOutput: previousOutput,
-			Script:         sig,
-			WalletAddress:  base58.Decode(fromAddress),
-		})
-	}
-	tx.Inputs = txIns
-	txHash := tx.generateHash()
-	tx.Hash = txHash
-	return &tx, nil
+// NewTransactionManyRecievers create transaction with output for every receiver, change is returned to the sender
+// without NodeID. Use NewTransactionWithOptions to keep change staked
+func NewTransactionManyRecievers(
+	unspents []TransactionInputOutpoint,
+	key HD,
+	fromAddress string,
+	receivers []Receiver,
+	commission Amount,
+) (*Tx, error) {
+	return NewTransactionWithOptions(unspents, key, fromAddress, receivers, commission, TxOptions{})
 }
 
-type Receiver struct {
-	Wallet string
-	Amount uint64
-	NodeID NodeID
+// Options of transaction builder
+type TxOptions struct {
+	ChangeNodeID NodeID   // NodeID of change output, change stays staked to the node if it is set, see PurposeChange
+	LockTime     uint32   // block height or unix time, see LockAtHeight and LockAtTime, it is signed with outputs
+	Sequences    []uint32 // Sequence of inputs in order of unspents, DefaultSequence after the end of slice, not signed
 }
 
-func NewTransactionManyRecievers(
+// NewTransactionWithOptions create transaction, which spends all unspents: the first output is commission, then an
+// output for every receiver and change to fromAddress, if something is left. Every builder of transactions with
+// receivers uses it, so NodeID is set by the same rules: receiver output has NodeID of receiver (see Receiver.Purpose)
+// and change output has opts.ChangeNodeID. Inputs are signed after LockTime is set, it is a part of signed message.
+// Sequence values are not signed, they are set after signing and only hash of transaction covers them
+func NewTransactionWithOptions(
 	unspents []TransactionInputOutpoint,
 	key HD,
 	fromAddress string,
 	receivers []Receiver,
-	commission uint64,
+	commission Amount,
+	opts TxOptions,
 ) (*Tx, error) {
-	totalin := int64(0)
-	totalout := int64(0)
-
-	for _, previousOutput := range unspents {
-		totalin += int64(previousOutput.Value)
+	if len(opts.Sequences) > len(unspents) {
+		return nil, errors.Errorf("Sequences are set for %d inputs, transaction has %d inputs", len(opts.Sequences), len(unspents))
 	}
-
+	totalin, err := SumUnspents(unspents)
+	if err != nil {
+		return nil, err
+	}
+	totalout := commission
 	for _, receiver := range receivers {
-		totalout += int64(receiver.Amount)
+		if totalout, err = totalout.Add(receiver.Amount); err != nil {
+			return nil, err
+		}
 	}
 
 	index := uint32(0)
 
-	txIns := make([]TransactionInput, 0)
-
 	txOuts := make([]TransactionOutput, 0)
 
-	txOuts = append(txOuts, TransactionOutput{
-		Index: index,
-		Value: commission,
-	})
+	commissionOut, err := NewOutput(PurposeCommission, index, "", commission, NodeID{})
+	if err != nil {
+		return nil, err
+	}
+	txOuts = append(txOuts, commissionOut)
 
 	for _, receiver := range receivers {
 		index++
-		txOuts = append(txOuts, TransactionOutput{
-			Index:         index,
-			Script:        base58.Decode(receiver.Wallet),
-			Value:         receiver.Amount,
-			WalletAddress: base58.Decode(receiver.Wallet),
-			NodeID:        receiver.NodeID,
-		})
+		receiverOut, err := NewOutput(receiver.Purpose(), index, receiver.Wallet, receiver.Amount, receiver.NodeID)
+		if err != nil {
+			return nil, err
+		}
+		if len(receiver.Payload) > 0 {
+			if err := receiverOut.SetPayload(receiver.Payload); err != nil {
+				return nil, err
+			}
+		}
+		txOuts = append(txOuts, receiverOut)
 	}
 
-	change := totalin - totalout - int64(commission)
-
-	if change < 0 {
-		return nil, errors.Errorf("Insufficient funds, total amount %d, commission %d, send amount %d", totalin, commission, totalout)
+	change, err := totalin.Sub(totalout)
+	if err != nil {
+		return nil, errors.Errorf("Insufficient funds, total amount %d, commission %d, send amount %d", totalin, commission, totalout-commission)
 	} else if change > 0 {
 		// My address
 		index++
-		txOuts = append(txOuts, TransactionOutput{
-			Index:         index,
-			Script:        base58.Decode(fromAddress),
-			Value:         uint64(change),
-			WalletAddress: base58.Decode(fromAddress),
-		})
+		changeOut, err := NewOutput(PurposeChange, index, fromAddress, change, opts.ChangeNodeID)
+		if err != nil {
+			return nil, err
+		}
+		txOuts = append(txOuts, changeOut)
 	}
 
 	tx := Tx{
 		Version:  1,
-		LockTime: 0,
+		LockTime: opts.LockTime,
+		Inputs:   make([]TransactionInput, 0, len(unspents)),
 		Outputs:  txOuts,
 	}
 
+	if err := tx.addSignedInputs(unspents, key, fromAddress); err != nil {
+		return nil, err
+	}
+	for i, sequence := range opts.Sequences {
+		tx.Inputs[i].Sequence = sequence
+	}
+	txHash := tx.GenerateHash()
+	tx.Hash = txHash
+	return &tx, nil
+}
+
+// addSignedInputs add inputs, which spend unspents of fromAddress, and sign them by key. Outputs must be set before,
+// signed message doesn't include inputs, so inputs of several keys can be added one after another
+func (tx *Tx) addSignedInputs(unspents []TransactionInputOutpoint, key HD, fromAddress string) error {
 	for _, previousOutput := range unspents {
 		sigMsg := tx.msgForSign(previousOutput.Hash, previousOutput.Index)
 		sig, err := cryptosign.CryptoSignDetached(sigMsg, key.privateKey)
 		if err != 0 {
-			return nil, errors.Errorf("Error on sign message")
+			return errors.Errorf("Error on sign message")
 		}
-		txIns = append(txIns, TransactionInput{
+		tx.Inputs = append(tx.Inputs, TransactionInput{
 			PublicKey:      key.publicKey,
-			Sequence:       1,
+			Sequence:       DefaultSequence,
 			PreviousOutput: previousOutput,
 			Script:         sig,
 			WalletAddress:  base58.Decode(fromAddress),
 		})
 	}
-	tx.Inputs = txIns
-	txHash := tx.generateHash()
-	tx.Hash = txHash
-	return &tx, nil
+	return nil
 }
 
 // msgForSign return msg for sign transaction inputs
-func (tx *Tx) msgForSign(hash [32]byte, index uint32) []byte {
+func (tx *Tx) msgForSign(hash Hash, index uint32) []byte {
 	txOutSlices := make([][]byte, 0)
 	for _, txOut := range tx.Outputs {
 		txOutSlices = append(txOutSlices, txOut.msgForSign())
@@ -205,8 +190,38 @@ func (tx *Tx) msgForSign(hash [32]byte, index uint32) []byte {
 	return helpers.ConcatByteArray(txSlices)
 }
 
-// generateHash return generated hash
-func (tx *Tx) generateHash() [32]byte {
+// Verify check that stored hash matches the transaction content and every input is signed by its public key
+func (tx *Tx) Verify() error {
+	if tx.GenerateHash() != tx.Hash {
+		return errors.Errorf("Transaction hash mismatch")
+	}
+	for i := range tx.Inputs {
+		if !tx.verifyInput(i) {
+			return errors.Errorf("Invalid signature of input %d", i)
+		}
+	}
+	return nil
+}
+
+// verifyInput check that input i is signed by its public key
+func (tx *Tx) verifyInput(i int) bool {
+	txIn := tx.Inputs[i]
+	sigMsg := tx.msgForSign(txIn.PreviousOutput.Hash, txIn.PreviousOutput.Index)
+	return cryptosign.CryptoSignVerifyDetached(txIn.Script, sigMsg, txIn.PublicKey) == 0
+}
+
+// GenerateHash return hash of transaction content, Hash field is not used
+func (tx *Tx) GenerateHash() Hash {
+	return DHASH(tx.serialize())
+}
+
+// Size return length of serialized transaction, which is used for hash
+func (tx *Tx) Size() int {
+	return len(tx.serialize())
+}
+
+// serialize transaction for hash
+func (tx *Tx) serialize() []byte {
 	txInSlices := make([][]byte, 0)
 	for _, txIn := range tx.Inputs {
 		txInSlices = append(txInSlices, txIn.forBlkHash())
@@ -226,51 +241,11 @@ func (tx *Tx) generateHash() [32]byte {
 		txOutSlice,
 	}
 
-	msg := helpers.ConcatByteArray(txSlices)
-	return DHASH(msg)
+	return helpers.ConcatByteArray(txSlices)
 }
 
 // Double sha256 hash
-func DHASH(data []byte) [32]byte {
+func DHASH(data []byte) Hash {
 	sum := sha256.Sum256(data)
-	sum = sha256.Sum256(sum[:])
-	return sum
-}
-
-// Marshal Tx to json string
-func (tx *Tx) MarshalJSON() ([]byte, error) {
-	type Alias Tx
-	return json.Marshal(&struct {
-		Hash string `json:"hash"`
-		*Alias
-	}{
-		Hash:  hex.EncodeToString(tx.Hash[:]),
-		Alias: (*Alias)(tx),
-	})
-}
-
-// UnmarshalJSON custom json convert
-func (tx *Tx) UnmarshalJSON(data []byte) error {
-	type Alias Tx
-	aux := &struct {
-		Hash string `json:"hash"`
-		*Alias
-	}{
-		Alias: (*Alias)(tx),
-	}
-	if err := json.Unmarshal(data, &aux); err != nil {
-		return err
-	}
-
-	pHash, err := hex.DecodeString(aux.Hash)
-	if err != nil {
-		return err
-	}
-
-	var hash [32]byte
-	if len(pHash) == 32 {
-		copy(hash[:], pHash[:32])
-	}
-	tx.Hash = hash
-	return nil
+	return sha256.Sum256(sum[:])
 }
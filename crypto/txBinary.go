@@ -0,0 +1,203 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/go-errors/errors"
+	"github.com/velas/GoVelas/crypto/helpers"
+)
+
+// GoVelasTxMagic starts transaction in GoVelas binary form. The form is private to GoVelas, it is used to pass
+// transactions between GoVelas tools and isn't the wire format of node, so node doesn't accept it
+const GoVelasTxMagic = "GVTX\x01"
+
+// EncodeGoVelas encode transaction to GoVelas binary form: GoVelasTxMagic, version, lock time and stored hash, then
+// count of inputs and outputs, variable fields are prefixed with uvarint length. Unlike serialize, which is used for
+// hash and doesn't keep field lengths, the form can be decoded back with DecodeGoVelas
+func (tx *Tx) EncodeGoVelas() []byte {
+	buf := &bytes.Buffer{}
+	buf.WriteString(GoVelasTxMagic)
+	buf.Write(helpers.UInt32ToBytes(tx.Version))
+	buf.Write(helpers.UInt32ToBytes(tx.LockTime))
+	buf.Write(tx.Hash[:])
+
+	writeUvarint(buf, uint64(len(tx.Inputs)))
+	for _, txIn := range tx.Inputs {
+		buf.Write(txIn.PreviousOutput.ToBytes())
+		buf.Write(helpers.UInt32ToBytes(txIn.Sequence))
+		writeVarBytes(buf, txIn.Script)
+		writeVarBytes(buf, txIn.PublicKey)
+		writeVarBytes(buf, txIn.WalletAddress)
+	}
+
+	writeUvarint(buf, uint64(len(tx.Outputs)))
+	for _, txOut := range tx.Outputs {
+		buf.Write(helpers.UInt32ToBytes(txOut.Index))
+		buf.Write(helpers.UInt64ToBytes(txOut.Value))
+		writeVarBytes(buf, txOut.Script)
+		writeVarBytes(buf, txOut.Payload)
+		writeVarBytes(buf, txOut.WalletAddress)
+		buf.Write(txOut.NodeID[:])
+	}
+	return buf.Bytes()
+}
+
+// DecodeGoVelas decode transaction from GoVelas binary form of EncodeGoVelas, whole data must be used
+func (tx *Tx) DecodeGoVelas(data []byte) error {
+	if !bytes.HasPrefix(data, []byte(GoVelasTxMagic)) {
+		return errors.Errorf("Transaction isn't in GoVelas binary form")
+	}
+	r := &binaryReader{data: data[len(GoVelasTxMagic):]}
+	decoded := Tx{
+		Version:  r.uint32(),
+		LockTime: r.uint32(),
+		Hash:     r.hash(),
+	}
+
+	count := r.count(txInBinaryMinLen)
+	for i := 0; i < count && r.err == nil; i++ {
+		txIn := TransactionInput{
+			PreviousOutput: TransactionInputOutpoint{Hash: r.hash(), Index: r.uint32(), Value: r.uint64()},
+			Sequence:       r.uint32(),
+		}
+		txIn.Script = r.varBytes()
+		txIn.PublicKey = r.varBytes()
+		txIn.WalletAddress = r.varBytes()
+		decoded.Inputs = append(decoded.Inputs, txIn)
+	}
+
+	count = r.count(txOutBinaryMinLen)
+	for i := 0; i < count && r.err == nil; i++ {
+		txOut := TransactionOutput{Index: r.uint32(), Value: r.uint64()}
+		txOut.Script = r.varBytes()
+		txOut.Payload = r.varBytes()
+		txOut.WalletAddress = r.varBytes()
+		txOut.NodeID = NodeID(r.hash())
+		decoded.Outputs = append(decoded.Outputs, txOut)
+	}
+
+	if r.err != nil {
+		return r.err
+	}
+	if len(r.data) > 0 {
+		return errors.Errorf("Transaction has %d extra bytes", len(r.data))
+	}
+	*tx = decoded
+	return nil
+}
+
+// DecodeTx decode transaction in json or in GoVelas binary form, raw or in hex, see EncodeGoVelas. The format is
+// detected by content
+func DecodeTx(data []byte) (*Tx, error) {
+	tx := &Tx{}
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		if err := json.Unmarshal(trimmed, tx); err != nil {
+			return nil, errors.New(err)
+		}
+		return tx, nil
+	}
+	if decoded, err := hex.DecodeString(string(trimmed)); err == nil && len(decoded) > 0 {
+		data = decoded
+	}
+	if err := tx.DecodeGoVelas(data); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// Minimal lengths of input and output in binary form, they limit count of items, so invalid count can't allocate much
+const (
+	txInBinaryMinLen  = 44 + 4 + 3
+	txOutBinaryMinLen = 4 + 8 + 3 + 32
+)
+
+// writeUvarint write unsigned varint
+func writeUvarint(buf *bytes.Buffer, v uint64) {
+	varint := make([]byte, binary.MaxVarintLen64)
+	buf.Write(varint[:binary.PutUvarint(varint, v)])
+}
+
+// writeVarBytes write bytes prefixed with length
+func writeVarBytes(buf *bytes.Buffer, data []byte) {
+	writeUvarint(buf, uint64(len(data)))
+	buf.Write(data)
+}
+
+// Reader of GoVelas binary form, the first error stops reading and zero values are returned after it
+type binaryReader struct {
+	data []byte
+	err  error
+}
+
+// next return n bytes and move forward
+func (r *binaryReader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n > len(r.data) {
+		r.err = errors.Errorf("Transaction data is truncated")
+		return nil
+	}
+	data := r.data[:n]
+	r.data = r.data[n:]
+	return data
+}
+
+func (r *binaryReader) uint32() uint32 {
+	if data := r.next(4); data != nil {
+		return helpers.BytesToUInt32(data)
+	}
+	return 0
+}
+
+func (r *binaryReader) uint64() uint64 {
+	if data := r.next(8); data != nil {
+		return helpers.BytesToUInt64(data)
+	}
+	return 0
+}
+
+func (r *binaryReader) hash() (hash Hash) {
+	copy(hash[:], r.next(len(hash)))
+	return hash
+}
+
+// uvarint read length or count, it can't be greater than count of remaining bytes
+func (r *binaryReader) uvarint() int {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(r.data)
+	if n <= 0 {
+		r.err = errors.Errorf("Transaction has invalid length prefix")
+		return 0
+	}
+	r.data = r.data[n:]
+	if v > uint64(len(r.data)) {
+		r.err = errors.Errorf("Transaction data is truncated")
+		return 0
+	}
+	return int(v)
+}
+
+// count read count of items, each item has at least minLen bytes
+func (r *binaryReader) count(minLen int) int {
+	count := r.uvarint()
+	if r.err == nil && count*minLen > len(r.data) {
+		r.err = errors.Errorf("Transaction data is truncated")
+		return 0
+	}
+	return count
+}
+
+// varBytes read bytes prefixed with length, empty bytes are returned as nil
+func (r *binaryReader) varBytes() []byte {
+	n := r.uvarint()
+	if n == 0 {
+		return nil
+	}
+	return append([]byte(nil), r.next(n)...)
+}
@@ -0,0 +1,63 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+)
+
+func TestDecodeTx(t *testing.T) {
+	hd, _ := GenerateHD()
+	wallet, _ := hd.ToWallet()
+	unspents := []TransactionInputOutpoint{
+		{Hash: DHASH([]byte("first")), Index: 0, Value: 5000000},
+		{Hash: DHASH([]byte("second")), Index: 1, Value: 7000000},
+	}
+	nodeID := NodeID(DHASH([]byte("node")))
+	tx, err := NewTransaction(unspents, 1000, *hd, wallet.Base58Address, "VLa1hi77ZXD2BSWDD9wQe8vAhejXyS7vBM4", 1000000, nodeID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Outputs[1].SetPayload([]byte("invoice 1")); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Sign(*hd); err != nil {
+		t.Fatal(err)
+	}
+	jsonData, _ := json.Marshal(tx)
+	binaryData := tx.EncodeGoVelas()
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr bool
+	}{
+		{name: "JSON", data: jsonData, wantErr: false},
+		{name: "Binary", data: binaryData, wantErr: false},
+		{name: "Hex", data: []byte(hex.EncodeToString(binaryData) + "\n"), wantErr: false},
+		{name: "Truncated", data: binaryData[:len(binaryData)-1], wantErr: true},
+		{name: "Extra bytes", data: append(append([]byte{}, binaryData...), 0), wantErr: true},
+		{name: "Invalid count", data: append(append([]byte{}, binaryData[:len(GoVelasTxMagic)+40]...), 0xff, 0xff, 0xff, 0xff, 0x0f), wantErr: true},
+		{name: "Without magic", data: binaryData[len(GoVelasTxMagic):], wantErr: true},
+		{name: "Invalid JSON", data: []byte(`{"hash": 1}`), wantErr: true},
+		{name: "Empty", data: []byte{}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeTx(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DecodeTx() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if err := got.Verify(); err != nil {
+				t.Errorf("Verify() of decoded transaction error = %v", err)
+			}
+			gotJSON, _ := json.Marshal(got)
+			if string(gotJSON) != string(jsonData) {
+				t.Errorf("DecodeTx() got = %s, want %s", gotJSON, jsonData)
+			}
+		})
+	}
+}
@@ -21,9 +21,9 @@ type TransactionInput struct {
 
 // Previous transaction out
 type TransactionInputOutpoint struct {
-	Hash  [32]byte `json:"hash"`  // The hash of the referenced transaction
-	Index uint32   `json:"index"` // The index of the specific output in the transaction. The first output is 0, etc.
-	Value uint64   `json:"value"` // Transaction Value
+	Hash  Hash   `json:"hash"`  // The hash of the referenced transaction
+	Index uint32 `json:"index"` // The index of the specific output in the transaction. The first output is 0, etc.
+	Value uint64 `json:"value"` // Transaction Value
 }
 
 // ToBytes convert TransactionInputOutpoint to bytes slice
@@ -37,42 +37,6 @@ func (tio TransactionInputOutpoint) ToBytes() []byte {
 	return helpers.ConcatByteArray(slices)
 }
 
-// MarshalJSON custom json convert
-func (tio *TransactionInputOutpoint) MarshalJSON() ([]byte, error) {
-	type Alias TransactionInputOutpoint
-	return json.Marshal(&struct {
-		Hash string `json:"hash"`
-		*Alias
-	}{
-		Hash:  hex.EncodeToString(tio.Hash[:]),
-		Alias: (*Alias)(tio),
-	})
-}
-
-// UnmarshalJSON custom json convert
-func (tio *TransactionInputOutpoint) UnmarshalJSON(data []byte) error {
-	type Alias TransactionInputOutpoint
-	aux := &struct {
-		Hash string `json:"hash"`
-		*Alias
-	}{
-		Alias: (*Alias)(tio),
-	}
-	if err := json.Unmarshal(data, &aux); err != nil {
-		return err
-	}
-
-	hashStop, err := hex.DecodeString(aux.Hash)
-	if err != nil {
-		return err
-	}
-
-	var hash [32]byte
-	copy(hash[:], hashStop[:32])
-	tio.Hash = hash
-	return nil
-}
-
 // Transaction input to byte array for generate hash
 func (ti *TransactionInput) forBlkHash() []byte {
 	slices := [][]byte{
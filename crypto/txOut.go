@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"github.com/btcsuite/btcutil/base58"
+	"github.com/go-errors/errors"
 	"github.com/velas/GoVelas/crypto/helpers"
 )
 
@@ -13,7 +14,7 @@ type TransactionOutput struct {
 	Index         uint32 `json:"index"`
 	Value         uint64 `json:"value"`     // Transaction Value
 	Script        []byte `json:"pk_script"` // Usually contains the public key as a script setting up conditions to claim this output.
-	Payload       []byte `json:"payload"`
+	Payload       []byte `json:"payload"`   // memo data, up to MaxPayloadSize bytes, see SetPayload
 	WalletAddress []byte `json:"wallet_address,omitempty"`
 	NodeID        NodeID `json:"node_id"` // usually contains the public key of node for request to be part of commission
 }
@@ -34,6 +35,71 @@ func (n NodeID) IsEmpty() bool {
 	return false
 }
 
+// Purpose of transaction output, it defines which fields of output are set
+type OutputPurpose int
+
+const (
+	// Commission output, the first output of transaction, it has neither script nor NodeID
+	PurposeCommission OutputPurpose = iota
+	// Payment to receiver, it never has NodeID
+	PurposePayment
+	// Stake to validator, NodeID of validator is required
+	PurposeStake
+	// Change returned to sender, NodeID is optional and keeps the change staked to the same validator
+	PurposeChange
+)
+
+// String return name of purpose
+func (p OutputPurpose) String() string {
+	switch p {
+	case PurposeCommission:
+		return "commission"
+	case PurposePayment:
+		return "payment"
+	case PurposeStake:
+		return "stake"
+	case PurposeChange:
+		return "change"
+	}
+	return "unknown"
+}
+
+// NewOutput create transaction output for purpose, NodeID is set only where purpose allows it. Address is not used for
+// commission output
+func NewOutput(purpose OutputPurpose, index uint32, address string, value Amount, nodeID NodeID) (TransactionOutput, error) {
+	output := TransactionOutput{
+		Index: index,
+		Value: uint64(value),
+	}
+	switch purpose {
+	case PurposeCommission:
+		if !nodeID.IsEmpty() {
+			return output, errors.Errorf("Commission output can't have node id")
+		}
+		return output, nil
+	case PurposePayment:
+		if !nodeID.IsEmpty() {
+			return output, errors.Errorf("Payment output can't have node id, use stake output")
+		}
+	case PurposeStake:
+		if nodeID.IsEmpty() {
+			return output, errors.Errorf("Stake output requires node id")
+		}
+	case PurposeChange:
+	default:
+		return output, errors.Errorf("Unknown output purpose %d", purpose)
+	}
+	output.Script = base58.Decode(address)
+	output.WalletAddress = base58.Decode(address)
+	output.NodeID = nodeID
+	return output, nil
+}
+
+// Amount return value of output
+func (to *TransactionOutput) Amount() Amount {
+	return Amount(to.Value)
+}
+
 // forBlkHash - convert transaction output to byte slice
 func (to *TransactionOutput) forBlkHash() []byte {
 	slices := [][]byte{
@@ -41,9 +107,7 @@ func (to *TransactionOutput) forBlkHash() []byte {
 		helpers.UInt64ToBytes(to.Value), // 8 bytes
 		to.Script,                       // Script
 	}
-	if !to.NodeID.IsEmpty() {
-		slices = append(slices, to.NodeID[:])
-	}
+	slices = append(slices, to.optionalFields()...)
 
 	return helpers.ConcatByteArray(slices)
 }
@@ -55,14 +119,29 @@ func (to *TransactionOutput) msgForSign() []byte {
 		helpers.UInt64ToBytes(to.Value), // 8 bytes
 		to.Script,                       // sc.ScriptLength
 	}
-
-	if !to.NodeID.IsEmpty() {
-		slices = append(slices, to.NodeID[:])
-	}
+	slices = append(slices, to.optionalFields()...)
 
 	return helpers.ConcatByteArray(slices)
 }
 
+// optionalFields return NodeID and payload for hash and signature of output. NodeID is added only if it is set.
+// Payload is added only if it is set: NodeID is added then even if it is empty, and payload follows with length of 4
+// bytes, so output with NodeID can't have the same bytes as output with payload. Layout of payload is not confirmed
+// against the node implementation, outputs without payload keep layout of node
+func (to *TransactionOutput) optionalFields() [][]byte {
+	if len(to.Payload) == 0 {
+		if to.NodeID.IsEmpty() {
+			return nil
+		}
+		return [][]byte{to.NodeID[:]}
+	}
+	return [][]byte{
+		to.NodeID[:], // 32 bytes
+		helpers.UInt32ToBytes(uint32(len(to.Payload))), // 4 bytes
+		to.Payload,
+	}
+}
+
 // MarshalJSON custom json convert
 func (to *TransactionOutput) MarshalJSON() ([]byte, error) {
 	type Alias TransactionOutput
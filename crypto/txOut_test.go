@@ -0,0 +1,127 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestNewOutput(t *testing.T) {
+	const address = "VLa1hi77ZXD2BSWDD9wQe8vAhejXyS7vBM4"
+	const (
+		indexAndValue = "01000000" + "e803000000000000" // index 1, value 1000
+		script        = "0ff4a10092749f54d42db35f33be71aad37eb0cad8b35f517a3b"
+	)
+	nodeID := NodeID{0xaa}
+	nodeIDHex := "aa" + strings.Repeat("00", 31)
+
+	tests := []struct {
+		name    string
+		purpose OutputPurpose
+		nodeID  NodeID
+		wantMsg string
+		wantErr bool
+	}{
+		{name: "Commission", purpose: PurposeCommission, wantMsg: indexAndValue},
+		{name: "Commission with node id", purpose: PurposeCommission, nodeID: nodeID, wantErr: true},
+		{name: "Payment", purpose: PurposePayment, wantMsg: indexAndValue + script},
+		{name: "Payment with node id", purpose: PurposePayment, nodeID: nodeID, wantErr: true},
+		{name: "Stake", purpose: PurposeStake, nodeID: nodeID, wantMsg: indexAndValue + script + nodeIDHex},
+		{name: "Stake without node id", purpose: PurposeStake, wantErr: true},
+		{name: "Change", purpose: PurposeChange, wantMsg: indexAndValue + script},
+		{name: "Staked change", purpose: PurposeChange, nodeID: nodeID, wantMsg: indexAndValue + script + nodeIDHex},
+		{name: "Unknown purpose", purpose: OutputPurpose(10), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewOutput(tt.purpose, 1, address, 1000, tt.nodeID)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewOutput() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if msg := hex.EncodeToString(got.msgForSign()); msg != tt.wantMsg {
+				t.Errorf("msgForSign() = %s, want %s", msg, tt.wantMsg)
+			}
+			if msg := hex.EncodeToString(got.forBlkHash()); msg != tt.wantMsg {
+				t.Errorf("forBlkHash() = %s, want %s", msg, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestNewTransaction_OutputPurposes(t *testing.T) {
+	const address = "VLa1hi77ZXD2BSWDD9wQe8vAhejXyS7vBM4"
+	const (
+		script = "0ff4a10092749f54d42db35f33be71aad37eb0cad8b35f517a3b"
+		// previous output hash, index, version and lock time
+		header     = "01" + "00000000000000000000000000000000000000000000000000000000000000" + "00000000" + "01000000" + "00000000"
+		commission = "00000000" + "40420f0000000000"
+	)
+	hd, _ := GenerateHD()
+	unspents := []TransactionInputOutpoint{{Hash: Hash{0x01}, Index: 0, Value: 5000000}}
+	nodeID := NodeID{0xaa}
+	nodeIDHex := "aa" + strings.Repeat("00", 31)
+	payment := Receiver{Wallet: address, Amount: 1000}
+	stake := Receiver{Wallet: address, Amount: 2000, NodeID: nodeID}
+
+	tests := []struct {
+		name    string
+		build   func() (*Tx, error)
+		wantMsg string
+	}{
+		{
+			name: "Payment and change",
+			build: func() (*Tx, error) {
+				return NewTransaction(unspents, 1000, *hd, address, address, 1000000, NodeID{})
+			},
+			wantMsg: header + commission + "01000000e803000000000000" + script + "0200000018053d0000000000" + script,
+		},
+		{
+			name: "Payment and staked change",
+			build: func() (*Tx, error) {
+				return NewTransaction(unspents, 1000, *hd, address, address, 1000000, nodeID)
+			},
+			wantMsg: header + commission + "01000000e803000000000000" + script + "0200000018053d0000000000" + script + nodeIDHex,
+		},
+		{
+			name: "Payment without change",
+			build: func() (*Tx, error) {
+				return NewTransaction(unspents, 4000000, *hd, address, address, 1000000, nodeID)
+			},
+			wantMsg: header + commission + "0100000000093d0000000000" + script,
+		},
+		{
+			name: "Payment, stake and change",
+			build: func() (*Tx, error) {
+				return NewTransactionManyRecievers(unspents, *hd, address, []Receiver{payment, stake}, 1000000)
+			},
+			wantMsg: header + commission + "01000000e803000000000000" + script + "02000000d007000000000000" + script + nodeIDHex +
+				"0300000048fd3c0000000000" + script,
+		},
+		{
+			name: "Payment, stake and staked change",
+			build: func() (*Tx, error) {
+				return NewTransactionWithOptions(unspents, *hd, address, []Receiver{payment, stake}, 1000000,
+					TxOptions{ChangeNodeID: nodeID})
+			},
+			wantMsg: header + commission + "01000000e803000000000000" + script + "02000000d007000000000000" + script + nodeIDHex +
+				"0300000048fd3c0000000000" + script + nodeIDHex,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx, err := tt.build()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if msg := hex.EncodeToString(tx.msgForSign(unspents[0].Hash, unspents[0].Index)); msg != tt.wantMsg {
+				t.Errorf("msgForSign() = %s, want %s", msg, tt.wantMsg)
+			}
+			if err := tx.Verify(); err != nil {
+				t.Errorf("Verify() error = %v", err)
+			}
+		})
+	}
+}
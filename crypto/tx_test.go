@@ -0,0 +1,61 @@
+package crypto
+
+import (
+	"testing"
+)
+
+func TestTx_Verify(t *testing.T) {
+	hd, _ := GenerateHD()
+	wallet, _ := hd.ToWallet()
+	unspents := []TransactionInputOutpoint{
+		{Hash: DHASH([]byte("first")), Index: 0, Value: 5000000},
+		{Hash: DHASH([]byte("second")), Index: 1, Value: 7000000},
+	}
+	tests := []struct {
+		name    string
+		modify  func(tx *Tx)
+		wantErr bool
+	}{
+		{
+			name:    "Correct",
+			modify:  func(tx *Tx) {},
+			wantErr: false,
+		},
+		{
+			name: "changed output",
+			modify: func(tx *Tx) {
+				tx.Outputs[1].Value++
+				tx.Hash = tx.GenerateHash()
+			},
+			wantErr: true,
+		},
+		{
+			name: "changed hash",
+			modify: func(tx *Tx) {
+				tx.Hash[0] ^= 0xff
+			},
+			wantErr: true,
+		},
+		{
+			name: "foreign public key",
+			modify: func(tx *Tx) {
+				other, _ := GenerateHD()
+				tx.Inputs[1].PublicKey = other.publicKey
+				tx.Hash = tx.GenerateHash()
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx, err := NewTransaction(unspents, 1000, *hd, wallet.Base58Address, "VLa1hi77ZXD2BSWDD9wQe8vAhejXyS7vBM4", 1000000, NodeID{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.modify(tx)
+			if err := tx.Verify(); (err != nil) != tt.wantErr {
+				t.Errorf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -63,15 +63,14 @@ func IsWalletAddress(addr string) bool {
 	address := base58.Decode(addr)
 
 	lenAddress := len(address)
+	if lenAddress <= len(Version)+addressChecksumLen {
+		return false
+	}
 	payload := address[:lenAddress-addressChecksumLen]
 	version := address[:2]
 	if !bytes.Equal(version, Version) {
 		return false
 	}
 	check := checksum(payload)
-	checkAddress := append(payload, check...)
-	if !bytes.Equal(address, checkAddress) {
-		return false
-	}
-	return true
+	return bytes.Equal(address[lenAddress-addressChecksumLen:], check)
 }
@@ -39,3 +39,23 @@ func TestCreateWallet(t *testing.T) {
 		})
 	}
 }
+
+func TestIsWalletAddress(t *testing.T) {
+	tests := []struct {
+		name string
+		addr string
+		want bool
+	}{
+		{name: "Correct", addr: "VLcN1dBy1VPc9bijr8rzeGbC78MCQ8DjwvS", want: true},
+		{name: "wrong checksum", addr: "VLcN1dBy1VPc9bijr8rzeGbC78MCQ8DjwvT", want: false},
+		{name: "empty", addr: "", want: false},
+		{name: "short", addr: "VLa", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsWalletAddress(tt.addr); got != tt.want {
+				t.Errorf("IsWalletAddress() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
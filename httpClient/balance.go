@@ -0,0 +1,117 @@
+package httpClient
+
+import (
+	"bytes"
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/go-errors/errors"
+	"github.com/velas/GoVelas/crypto"
+)
+
+// Options of GetBalanceSummary
+type BalanceOptions struct {
+	UTXO *UTXOManager // optional, outputs reserved or spent locally by manager are not spendable
+	// Optional, pending transactions are taken from transactions tracked by watcher, address must be subscribed and
+	// polled. Without watcher pending amounts are zero
+	Watcher *Watcher
+}
+
+// Balance of address split by state of outputs
+type BalanceSummary struct {
+	Confirmed       crypto.Amount // confirmed outputs without staking ones, which are not spent by pending transactions
+	Spendable       crypto.Amount // confirmed outputs, which are not reserved or spent by UTXO manager, it equals Confirmed without manager
+	Staked          crypto.Amount // confirmed staking outputs, which are not spent by pending transactions
+	PendingIncoming crypto.Amount // outputs to address in pending transactions, change included
+	PendingOutgoing crypto.Amount // outputs of address spent by pending transactions
+}
+
+// Total return confirmed and staked funds together with pending incoming funds, which are expected after confirmation
+// of pending transactions
+func (bs *BalanceSummary) Total() (crypto.Amount, error) {
+	total, err := bs.Confirmed.Add(bs.Staked)
+	if err != nil {
+		return 0, err
+	}
+	return total.Add(bs.PendingIncoming)
+}
+
+// GetBalanceSummary combine unspents, staking unspents and pending transactions of address in one balance
+func (cl *Client) GetBalanceSummary(address string, opts BalanceOptions) (*BalanceSummary, error) {
+	if !crypto.IsWalletAddress(address) {
+		return nil, errors.Errorf("invalid address %s", address)
+	}
+	unspents, err := cl.Wallet.GetUnspent(address)
+	if err != nil {
+		return nil, err
+	}
+	stakingUnspents, err := cl.Wallet.GetUnspentForStaking(address)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &BalanceSummary{}
+	pending := make(map[outpointKey]bool)
+	if opts.Watcher != nil {
+		script := base58.Decode(address)
+		for _, watched := range opts.Watcher.Tracked() {
+			if !watched.Block.IsEmpty() || watched.Tx == nil || watched.Tx.Tx == nil {
+				continue
+			}
+			for _, txIn := range watched.Tx.Inputs {
+				if !bytes.Equal(txIn.WalletAddress, script) {
+					continue
+				}
+				pending[keyOf(txIn.PreviousOutput)] = true
+				if summary.PendingOutgoing, err = summary.PendingOutgoing.Add(crypto.Amount(txIn.PreviousOutput.Value)); err != nil {
+					return nil, err
+				}
+			}
+			for _, txOut := range watched.Tx.Outputs {
+				if !bytes.Equal(txOut.WalletAddress, script) {
+					continue
+				}
+				if summary.PendingIncoming, err = summary.PendingIncoming.Add(txOut.Amount()); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	free := make(map[outpointKey]bool, len(unspents))
+	for _, unspent := range unspents {
+		free[keyOf(unspent)] = true
+		if pending[keyOf(unspent)] {
+			continue
+		}
+		if summary.Confirmed, err = summary.Confirmed.Add(crypto.Amount(unspent.Value)); err != nil {
+			return nil, err
+		}
+	}
+	for _, unspent := range stakingUnspents {
+		if free[keyOf(unspent)] || pending[keyOf(unspent)] {
+			continue
+		}
+		if summary.Staked, err = summary.Staked.Add(crypto.Amount(unspent.Value)); err != nil {
+			return nil, err
+		}
+	}
+	summary.Spendable = summary.Confirmed
+	if opts.UTXO != nil {
+		if err := opts.UTXO.Refresh(address); err != nil {
+			return nil, err
+		}
+		available, err := opts.UTXO.Available(address)
+		if err != nil {
+			return nil, err
+		}
+		summary.Spendable = 0
+		for _, unspent := range available {
+			if pending[keyOf(unspent)] {
+				continue
+			}
+			if summary.Spendable, err = summary.Spendable.Add(crypto.Amount(unspent.Value)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return summary, nil
+}
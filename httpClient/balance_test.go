@@ -0,0 +1,128 @@
+package httpClient
+
+import (
+	"github.com/velas/GoVelas/crypto"
+	"github.com/velas/GoVelas/crypto/staking"
+	"math"
+	"testing"
+)
+
+func TestClient_GetBalanceSummary(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
+	client := NewClient(srv.URL)
+	hd, _ := crypto.HDFromPrivateKeyHex(Pk)
+	wallet, _ := hd.ToWallet()
+	stakerHD, _ := crypto.HDFromPrivateKeyHex(Pk2)
+	staker, _ := stakerHD.ToWallet()
+	receiver := "VLa1hi77ZXD2BSWDD9wQe8vAhejXyS7vBM4"
+
+	unspents, _ := client.Wallet.GetUnspent(staker.Base58Address)
+	stake, err := staking.NewStake(unspents, *stakerHD, crypto.NodeID{1, 2, 3}, 1000000000, DefaultCommission)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Tx.Publish(*stake); err != nil {
+		t.Fatal(err)
+	}
+	srv.Mine()
+	sendTestTx(t, client, Pk, receiver, 1000)
+	m := client.NewUTXOManager(UTXOManagerOptions{})
+	if _, err := m.Reserve(wallet.Base58Address, 1); err != nil {
+		t.Fatal(err)
+	}
+	w := client.NewWatcher(WatcherOptions{}, wallet.Base58Address, receiver)
+	if err := w.Poll(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		address string
+		opts    BalanceOptions
+		want    BalanceSummary
+		wantErr bool
+	}{
+		{
+			name:    "Staked",
+			address: staker.Base58Address,
+			want: BalanceSummary{
+				Confirmed: 10000000000000 - 1000000000 - DefaultCommission,
+				Spendable: 10000000000000 - 1000000000 - DefaultCommission,
+				Staked:    1000000000,
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Without watcher",
+			address: wallet.Base58Address,
+			want:    BalanceSummary{Confirmed: 200000000, Spendable: 200000000},
+			wantErr: false,
+		},
+		{
+			name:    "Pending outgoing",
+			address: wallet.Base58Address,
+			opts:    BalanceOptions{Watcher: w},
+			want: BalanceSummary{
+				Confirmed:       200000000,
+				Spendable:       200000000,
+				PendingIncoming: 100000000 - 1000 - 1000000,
+				PendingOutgoing: 100000000,
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Reserved by manager",
+			address: wallet.Base58Address,
+			opts:    BalanceOptions{UTXO: m, Watcher: w},
+			want: BalanceSummary{
+				Confirmed:       200000000,
+				Spendable:       0,
+				PendingIncoming: 100000000 - 1000 - 1000000,
+				PendingOutgoing: 100000000,
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Pending incoming",
+			address: receiver,
+			opts:    BalanceOptions{Watcher: w},
+			want:    BalanceSummary{PendingIncoming: 1000},
+			wantErr: false,
+		},
+		{
+			name:    "Invalid address",
+			address: "invalid",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := client.GetBalanceSummary(tt.address, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetBalanceSummary() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && *got != tt.want {
+				t.Errorf("GetBalanceSummary() got = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+
+	srv.Mine()
+	if err := w.Poll(); err != nil {
+		t.Fatal(err)
+	}
+	got, err := client.GetBalanceSummary(receiver, BalanceOptions{Watcher: w})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total, err := got.Total(); err != nil || got.Confirmed != 1000 || got.PendingIncoming != 0 || total != 1000 {
+		t.Errorf("GetBalanceSummary() after mining got = %+v, total %d, error = %v", *got, total, err)
+	}
+
+	// total of amounts can't overflow
+	overflow := BalanceSummary{Confirmed: math.MaxUint64, PendingIncoming: 1}
+	if _, err := overflow.Total(); err != crypto.ErrAmountOverflow {
+		t.Errorf("Total() of overflow error = %v, want %v", err, crypto.ErrAmountOverflow)
+	}
+}
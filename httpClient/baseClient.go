@@ -5,6 +5,7 @@ package httpClient
 import (
 	"encoding/json"
 	"github.com/go-errors/errors"
+	"github.com/velas/GoVelas/httpClient/response"
 	"gopkg.in/resty.v1"
 )
 
@@ -39,3 +40,22 @@ func (bk *baseClient) ReadResponse(resp *resty.Response) ([]byte, error) {
 	}
 	return body, nil
 }
+
+// Get status of node, used by clients which depend on the current chain state
+func (bk *baseClient) nodeInfo() (*response.Node, error) {
+	resp, err := resty.
+		R().
+		Get(bk.baseAddress + "/api/v1/info")
+	if err != nil {
+		return nil, errors.New(err)
+	}
+	body, err := bk.ReadResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+	result := response.Node{}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, errors.New(err)
+	}
+	return &result, nil
+}
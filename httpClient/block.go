@@ -1,21 +1,26 @@
 package httpClient
 
 import (
+	"encoding/hex"
 	"encoding/json"
 	"github.com/go-errors/errors"
 	"github.com/velas/GoVelas/crypto"
 	"gopkg.in/resty.v1"
+	"sync"
 )
 
 // Block node client
 type Block struct {
-	bk *baseClient
+	bk    *baseClient
+	tx    *Tx        // transactions client, its HashListWorkers limits concurrent requests of blocks
+	chain chainIndex // hashes of main chain blocks found by GetRange
 }
 
 // create block node client
-func newBlockClient(bk *baseClient) *Block {
+func newBlockClient(bk *baseClient, tx *Tx) *Block {
 	return &Block{
 		bk: bk,
+		tx: tx,
 	}
 }
 
@@ -32,27 +37,27 @@ type Advice struct {
 
 // Header object response from node request
 type Header struct {
-	Type        uint32 `json:"type"`         // Block type
-	Hash        string `json:"hash"`         // Hash
-	Height      uint32 `json:"height"`       // Height
-	Size        uint64 `json:"size"`         // Size
-	Version     uint32 `json:"version"`      // Block version information (note, this is signed)
-	PrevBlock   string `json:"prev_block"`   // The hash value of the previous block this particular block references
-	MerkleRoot  string `json:"merkle_root"`  // The reference to a Merkle tree collection which is a hash of all transactions related to this block
-	Timestamp   uint32 `json:"timestamp"`    // A timestamp recording when this block was created (Will overflow in 2106[2])
-	Bits        uint32 `json:"bits"`         // Not used
-	Nonce       uint32 `json:"nonce"`        // The nonce used to generate this block… to allow variations of the header and compute different hashes
-	Seed        string `json:"seed"`         // The random seed, not used
-	TxnCount    uint32 `json:"txn_count"`    // Transaction count
-	AdviceCount uint32 `json:"advice_count"` // Advise list count
-	Script      string `json:"script"`       // The node's (block owner) signature
+	Type        uint32      `json:"type"`         // Block type
+	Hash        crypto.Hash `json:"hash"`         // Hash
+	Height      uint32      `json:"height"`       // Height
+	Size        uint64      `json:"size"`         // Size
+	Version     uint32      `json:"version"`      // Block version information (note, this is signed)
+	PrevBlock   crypto.Hash `json:"prev_block"`   // The hash value of the previous block this particular block references
+	MerkleRoot  crypto.Hash `json:"merkle_root"`  // The reference to a Merkle tree collection which is a hash of all transactions related to this block
+	Timestamp   uint32      `json:"timestamp"`    // A timestamp recording when this block was created (Will overflow in 2106[2])
+	Bits        uint32      `json:"bits"`         // Not used
+	Nonce       uint32      `json:"nonce"`        // The nonce used to generate this block… to allow variations of the header and compute different hashes
+	Seed        string      `json:"seed"`         // The random seed, not used
+	TxnCount    uint32      `json:"txn_count"`    // Transaction count
+	AdviceCount uint32      `json:"advice_count"` // Advise list count
+	Script      string      `json:"script"`       // The node's (block owner) signature
 }
 
 // Method for get block object
-func (blk *Block) GetByHash(hash string) (*BlockResponse, error) {
+func (blk *Block) GetByHash(hash crypto.Hash) (*BlockResponse, error) {
 	resp, err := resty.
 		R().
-		Get(blk.bk.baseAddress + "/api/v1/blocks/" + hash)
+		Get(blk.bk.baseAddress + "/api/v1/blocks/" + hash.String())
 	if err != nil {
 		return nil, err
 	}
@@ -66,3 +71,359 @@ func (blk *Block) GetByHash(hash string) (*BlockResponse, error) {
 	}
 	return &blockResponse, nil
 }
+
+// Maximum count of blocks, which follower and header chain request by one GetRange call
+const DefaultBlockRangeSize = 100
+
+// Method for get block object by height. Node has no request of block by height, the block is found by GetRange
+func (blk *Block) GetByHeight(height uint32) (*BlockResponse, error) {
+	blocks, err := blk.GetRange(height, height)
+	if err != nil {
+		return nil, err
+	}
+	return blocks[0], nil
+}
+
+// Method for get last block of the chain, hash of block is taken from node info
+func (blk *Block) GetLatest() (*BlockResponse, error) {
+	info, err := blk.bk.nodeInfo()
+	if err != nil {
+		return nil, err
+	}
+	if info.Blockchain == nil || info.Blockchain.CurrentHash.IsEmpty() {
+		return nil, errors.Errorf("node info doesn't contain current block hash")
+	}
+	return blk.GetByHash(info.Blockchain.CurrentHash)
+}
+
+// Method for get block header. Node has no separate request of headers, header is taken from block response
+func (blk *Block) GetHeader(hash crypto.Hash) (*Header, error) {
+	block, err := blk.GetByHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	if block.Header == nil {
+		return nil, errors.Errorf("block %s without header", hash)
+	}
+	return block.Header, nil
+}
+
+// Method for get block header by height, header is taken from block response of GetByHeight
+func (blk *Block) GetHeaderByHeight(height uint32) (*Header, error) {
+	block, err := blk.GetByHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	return block.Header, nil
+}
+
+// Method for get headers of blocks from one height to another, both are included. Headers are sorted by height
+func (blk *Block) GetHeadersByRange(from uint32, to uint32) ([]*Header, error) {
+	blocks, err := blk.GetRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	headers := make([]*Header, 0, len(blocks))
+	for _, block := range blocks {
+		headers = append(headers, block.Header)
+	}
+	return headers, nil
+}
+
+// Method for get blocks of the main chain from one height to another, both are included. Blocks are sorted by height.
+// Node has no request of blocks by height, so hashes of main chain blocks are found by links to previous blocks from
+// the latest block. The client keeps found hashes, so the next call walks only from the latest block down to the first
+// kept block of the same chain. Blocks of the range are requested concurrently, heights and links of them are checked
+func (blk *Block) GetRange(from uint32, to uint32) ([]*BlockResponse, error) {
+	known := make(map[crypto.Hash]*BlockResponse)
+	hashes, err := blk.mainChain(from, to, known)
+	if err != nil {
+		return nil, err
+	}
+
+	missing := make([]crypto.Hash, 0)
+	for _, hash := range hashes {
+		if known[hash] == nil {
+			missing = append(missing, hash)
+		}
+	}
+	found, err := blk.getBlocks(missing)
+	if err != nil {
+		return nil, err
+	}
+	for _, block := range found {
+		known[block.Header.Hash] = block
+	}
+
+	blocks := make([]*BlockResponse, 0, len(hashes))
+	for i, hash := range hashes {
+		block := known[hash]
+		if block == nil || block.Header.Hash != hash || block.Header.Height != from+uint32(i) ||
+			(i > 0 && block.Header.PrevBlock != hashes[i-1]) {
+			// node switched to another branch after the walk, the next call walks again
+			blk.chain.reset()
+			return nil, errors.Errorf("block %s doesn't link to block at height %d", hash, from+uint32(i)-1)
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks, nil
+}
+
+// Method for get hashes of main chain blocks from one height to another, both are included. Hashes are found like in
+// GetRange, but blocks of range aren't requested again
+func (blk *Block) GetHashesByRange(from uint32, to uint32) ([]crypto.Hash, error) {
+	return blk.mainChain(from, to, make(map[crypto.Hash]*BlockResponse))
+}
+
+// mainChain request the latest block and return hashes of main chain from height from to height to. The latest block
+// and blocks of range requested by the walk are added to known blocks
+func (blk *Block) mainChain(from uint32, to uint32, known map[crypto.Hash]*BlockResponse) ([]crypto.Hash, error) {
+	if from > to {
+		return nil, errors.Errorf("invalid range of heights %d-%d", from, to)
+	}
+	latest, err := blk.GetLatest()
+	if err != nil {
+		return nil, err
+	}
+	if latest.Header == nil {
+		return nil, errors.Errorf("block without header")
+	}
+	if latest.Header.Height < to {
+		return nil, errors.Errorf("block at height %d not found, height of chain is %d", to, latest.Header.Height)
+	}
+	known[latest.Header.Hash] = latest
+	return blk.chain.update(latest, from, to, known, blk.GetByHash)
+}
+
+// Hashes of main chain blocks by height, they are kept by block client between GetRange calls. Kept hashes are linked
+// to each other, so if hash of a block matches kept hash at its height, kept hashes below are of the same chain
+type chainIndex struct {
+	mu     sync.Mutex
+	low    uint32        // height of the first kept hash
+	hashes []crypto.Hash // hashes from height low
+}
+
+// update walk from the latest block down by links to previous blocks, until a block matches kept hash and kept hashes
+// reach height from. Kept hashes are replaced by hashes of the walk above the matched block. Blocks of the walk
+// between heights from and to are added to known blocks. Hashes from height from to height to are returned
+func (ci *chainIndex) update(latest *BlockResponse, from uint32, to uint32, known map[crypto.Hash]*BlockResponse,
+	getByHash func(crypto.Hash) (*BlockResponse, error)) ([]crypto.Hash, error) {
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+
+	// walked hashes are collected from the top down
+	walked := make([]crypto.Hash, 0)
+	current := latest.Header
+	for {
+		if hash, ok := ci.at(current.Height); ok && hash == current.Hash {
+			ci.hashes = ci.hashes[:current.Height-ci.low+1]
+			break
+		}
+		walked = append(walked, current.Hash)
+		if current.Height <= from && (len(ci.hashes) == 0 || current.Height < ci.low) {
+			// nothing kept below matches the walked chain
+			ci.low = current.Height
+			ci.hashes = nil
+			break
+		}
+		if current.Height == 0 {
+			return nil, errors.Errorf("block %s at height 0 doesn't match kept chain", current.Hash)
+		}
+		prev, err := ci.walkBack(current, from, to, known, getByHash)
+		if err != nil {
+			return nil, err
+		}
+		current = prev
+	}
+	for i := len(walked) - 1; i >= 0; i-- {
+		ci.hashes = append(ci.hashes, walked[i])
+	}
+
+	// kept hashes don't reach height from, the walk continues from the lowest kept block
+	if ci.low > from {
+		block, ok := known[ci.hashes[0]]
+		if !ok {
+			var err error
+			if block, err = getByHash(ci.hashes[0]); err != nil {
+				return nil, err
+			}
+			if block.Header == nil || block.Header.Hash != ci.hashes[0] || block.Header.Height != ci.low {
+				return nil, errors.Errorf("block %s isn't at height %d", ci.hashes[0], ci.low)
+			}
+		}
+		below := make([]crypto.Hash, ci.low-from)
+		for current := block.Header; current.Height > from; {
+			prev, err := ci.walkBack(current, from, to, known, getByHash)
+			if err != nil {
+				return nil, err
+			}
+			below[prev.Height-from] = prev.Hash
+			current = prev
+		}
+		ci.low = from
+		ci.hashes = append(below, ci.hashes...)
+	}
+	return append([]crypto.Hash{}, ci.hashes[from-ci.low:to-ci.low+1]...), nil
+}
+
+// walkBack request block previous to header and check its link, the block is added to known blocks, if its height is
+// from from to to
+func (ci *chainIndex) walkBack(header *Header, from uint32, to uint32, known map[crypto.Hash]*BlockResponse,
+	getByHash func(crypto.Hash) (*BlockResponse, error)) (*Header, error) {
+	prev, err := getByHash(header.PrevBlock)
+	if err != nil {
+		return nil, err
+	}
+	if prev.Header == nil || prev.Header.Hash != header.PrevBlock || prev.Header.Height+1 != header.Height {
+		return nil, errors.Errorf("block %s doesn't link to block at height %d", header.Hash, header.Height-1)
+	}
+	if prev.Header.Height >= from && prev.Header.Height <= to {
+		known[prev.Header.Hash] = prev
+	}
+	return prev.Header, nil
+}
+
+// at return kept hash at height
+func (ci *chainIndex) at(height uint32) (crypto.Hash, bool) {
+	if height < ci.low || height-ci.low >= uint32(len(ci.hashes)) {
+		return crypto.Hash{}, false
+	}
+	return ci.hashes[height-ci.low], true
+}
+
+// reset forget kept hashes
+func (ci *chainIndex) reset() {
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+	ci.low = 0
+	ci.hashes = nil
+}
+
+// getBlocks request blocks by hashes concurrently, HashListWorkers of transaction client limits count of requests
+func (blk *Block) getBlocks(hashes []crypto.Hash) ([]*BlockResponse, error) {
+	workers := blk.tx.HashListWorkers
+	if workers <= 0 {
+		workers = 1
+	}
+	if workers > len(hashes) {
+		workers = len(hashes)
+	}
+
+	blocks := make([]*BlockResponse, len(hashes))
+	errs := make([]error, len(hashes))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				blocks[job], errs[job] = blk.GetByHash(hashes[job])
+			}
+		}()
+	}
+	for job := range hashes {
+		jobs <- job
+	}
+	close(jobs)
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+		if blocks[i].Header == nil {
+			return nil, errors.Errorf("block %s without header", hashes[i])
+		}
+	}
+	return blocks, nil
+}
+
+// verifyTxHashes check that hashes of transactions match their content. Merkle root of header isn't checked, merkle
+// tree of node is not confirmed, so transactions are not bound to the header
+func (br *BlockResponse) verifyTxHashes() error {
+	for i, tx := range br.Transactions {
+		if tx.GenerateHash() != tx.Hash {
+			return errors.Errorf("hash of transaction %d doesn't match its content", i)
+		}
+	}
+	return nil
+}
+
+// Decode convert header to typed header with byte hashes
+func (h *Header) Decode() (*crypto.BlockHeader, error) {
+	seed, err := hex.DecodeString(h.Seed)
+	if err != nil {
+		return nil, errors.New(err)
+	}
+	script, err := hex.DecodeString(h.Script)
+	if err != nil {
+		return nil, errors.New(err)
+	}
+	return &crypto.BlockHeader{
+		Type:        h.Type,
+		Hash:        h.Hash,
+		Height:      h.Height,
+		Version:     h.Version,
+		PrevBlock:   h.PrevBlock,
+		MerkleRoot:  h.MerkleRoot,
+		Timestamp:   h.Timestamp,
+		Bits:        h.Bits,
+		Nonce:       h.Nonce,
+		Seed:        seed,
+		TxnCount:    h.TxnCount,
+		AdviceCount: h.AdviceCount,
+		Script:      script,
+	}, nil
+}
+
+// Verify check producer signature of block hash, advice list and hashes of transactions. Hash of block is not
+// recalculated from header fields and merkle root is not checked, layouts of node are not confirmed, so header fields and
+// transactions are not bound to the signed hash. The block must be signed by one of trusted keys, they are required.
+// Public key of the producer is returned
+func (br *BlockResponse) Verify(trusted [][]byte) ([]byte, error) {
+	if len(trusted) == 0 {
+		return nil, errors.Errorf("trusted keys of block producers are required")
+	}
+	return br.verify(func(header *crypto.BlockHeader, advices [][]byte) ([]byte, error) {
+		return header.VerifyProducer(advices, trusted)
+	})
+}
+
+// VerifyUntrusted check block like Verify, but the producer must be only one of advices of the block. Advices are
+// returned by the same node, so the check doesn't protect from a dishonest node
+func (br *BlockResponse) VerifyUntrusted() ([]byte, error) {
+	return br.verify(func(header *crypto.BlockHeader, advices [][]byte) ([]byte, error) {
+		return header.VerifyProducerUntrusted(advices)
+	})
+}
+
+// verify decode header and advices, check producer with the function, transaction count and hashes of transactions
+func (br *BlockResponse) verify(verifyProducer func(header *crypto.BlockHeader, advices [][]byte) ([]byte, error)) ([]byte, error) {
+	if br.Header == nil {
+		return nil, errors.Errorf("block without header")
+	}
+	header, err := br.Header.Decode()
+	if err != nil {
+		return nil, err
+	}
+	advices := make([][]byte, 0, len(br.Advices))
+	for _, advice := range br.Advices {
+		key, err := hex.DecodeString(advice.PublicKey)
+		if err != nil {
+			return nil, errors.New(err)
+		}
+		advices = append(advices, key)
+	}
+	producer, err := verifyProducer(header, advices)
+	if err != nil {
+		return nil, err
+	}
+	if int(header.TxnCount) != len(br.Transactions) {
+		return nil, errors.Errorf("transaction count %d doesn't match %d transactions", header.TxnCount, len(br.Transactions))
+	}
+	if err := br.verifyTxHashes(); err != nil {
+		return nil, err
+	}
+	return producer, nil
+}
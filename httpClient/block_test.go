@@ -1,16 +1,26 @@
 package httpClient
 
 import (
+	"encoding/hex"
+	"github.com/velas/GoVelas/crypto"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
 	"reflect"
+	"strings"
+	"sync/atomic"
 	"testing"
 )
 
 func TestBlock_GetByHash(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
 	type fields struct {
 		baseAddress string
 	}
 	type args struct {
-		hash string
+		hash crypto.Hash
 	}
 	tests := []struct {
 		name    string
@@ -21,9 +31,9 @@ func TestBlock_GetByHash(t *testing.T) {
 	}{
 		{
 			name:   "Normal test",
-			fields: fields{baseAddress: "https://testnet.velas.com"},
+			fields: fields{baseAddress: srv.URL},
 			args: args{
-				hash: "508e68a53e8e73e8d7b477e0c3c4dbed83a56899c8dcecabe12f79c42cbd1c87",
+				hash: srv.TipHash(),
 			},
 			want:    nil,
 			wantErr: false,
@@ -44,7 +54,7 @@ func TestBlock_GetByHash(t *testing.T) {
 				t.Errorf("GetByHash() got = %v, want %v", got, tt.want)
 			}
 			*/
-			if len(got.Transactions) > 0 && reflect.DeepEqual(got.Transactions[0].Hash, [32]byte{}) {
+			if len(got.Transactions) > 0 && got.Transactions[0].Hash.IsEmpty() {
 				t.Error("empty hash")
 			}
 			if got.Header.TxnCount != uint32(len(got.Transactions)) {
@@ -61,3 +71,318 @@ func TestBlock_GetByHash(t *testing.T) {
 		})
 	}
 }
+
+func TestBlock_GetByHeight(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
+	type args struct {
+		height uint32
+	}
+	tests := []struct {
+		name     string
+		args     args
+		wantTxns uint32
+		wantErr  bool
+	}{
+		{
+			name:     "Genesis",
+			args:     args{height: 0},
+			wantTxns: 0,
+			wantErr:  false,
+		},
+		{
+			name:     "Funded block",
+			args:     args{height: 1},
+			wantTxns: 1,
+			wantErr:  false,
+		},
+		{
+			name:    "Above tip",
+			args:    args{height: 100},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient(srv.URL)
+			got, err := client.Block.GetByHeight(tt.args.height)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetByHeight() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.Header.Height != tt.args.height {
+				t.Errorf("GetByHeight() height = %d, want %d", got.Header.Height, tt.args.height)
+			}
+			if got.Header.TxnCount != tt.wantTxns || uint32(len(got.Transactions)) != tt.wantTxns {
+				t.Errorf("GetByHeight() transactions = %d, want %d", len(got.Transactions), tt.wantTxns)
+			}
+		})
+	}
+}
+
+func TestBlock_GetLatest(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
+	client := NewClient(srv.URL)
+	got, err := client.Block.GetLatest()
+	if err != nil {
+		t.Fatalf("GetLatest() error = %v", err)
+	}
+	if got.Header.Hash != srv.TipHash() || int(got.Header.Height) != srv.Height() {
+		t.Errorf("GetLatest() got = %+v, want %s at %d", got.Header, srv.TipHash(), srv.Height())
+	}
+}
+
+func TestBlock_GetHeader(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
+	type args struct {
+		hash crypto.Hash
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantHeight uint32
+		wantErr    bool
+	}{
+		{
+			name:       "Tip",
+			args:       args{hash: srv.TipHash()},
+			wantHeight: uint32(srv.Height()),
+			wantErr:    false,
+		},
+		{
+			name:    "Unknown",
+			args:    args{hash: crypto.DHASH([]byte("unknown"))},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient(srv.URL)
+			got, err := client.Block.GetHeader(tt.args.hash)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetHeader() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.Hash != tt.args.hash || got.Height != tt.wantHeight {
+				t.Errorf("GetHeader() got = %+v, want height %d", got, tt.wantHeight)
+			}
+		})
+	}
+}
+
+func TestBlock_GetHeadersByRange(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
+	type args struct {
+		from uint32
+		to   uint32
+	}
+	tests := []struct {
+		name        string
+		args        args
+		wantHeights []uint32
+		wantErr     bool
+	}{
+		{
+			name:        "Whole chain",
+			args:        args{from: 0, to: 3},
+			wantHeights: []uint32{0, 1, 2, 3},
+			wantErr:     false,
+		},
+		{
+			name:        "Single",
+			args:        args{from: 2, to: 2},
+			wantHeights: []uint32{2},
+			wantErr:     false,
+		},
+		{
+			name:    "Reversed",
+			args:    args{from: 2, to: 1},
+			wantErr: true,
+		},
+		{
+			name:    "Above tip",
+			args:    args{from: 2, to: 5},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient(srv.URL)
+			got, err := client.Block.GetHeadersByRange(tt.args.from, tt.args.to)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetHeadersByRange() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			gotHeights := make([]uint32, 0)
+			for i, header := range got {
+				gotHeights = append(gotHeights, header.Height)
+				if i > 0 && header.PrevBlock != got[i-1].Hash {
+					t.Errorf("GetHeadersByRange() header %d doesn't link to previous", header.Height)
+				}
+			}
+			if !tt.wantErr && !reflect.DeepEqual(gotHeights, tt.wantHeights) {
+				t.Errorf("GetHeadersByRange() got = %v, want %v", gotHeights, tt.wantHeights)
+			}
+		})
+	}
+}
+
+func TestBlock_GetRange(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
+	srv.Mine()
+	sendTestTx(t, NewClient(srv.URL), Pk, "VLa1hi77ZXD2BSWDD9wQe8vAhejXyS7vBM4", 1000)
+	srv.Mine()
+	for i := 0; i < 10; i++ {
+		srv.Mine()
+	}
+	nodeURL, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxy := httputil.NewSingleHostReverseProxy(nodeURL)
+	requests := int32(0)
+	counted := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/api/v1/blocks/") {
+			atomic.AddInt32(&requests, 1)
+		}
+		proxy.ServeHTTP(w, r)
+	}))
+	defer counted.Close()
+	client := NewClient(counted.URL)
+
+	got, err := client.Block.GetRange(1, 6)
+	if err != nil {
+		t.Fatalf("GetRange() error = %v", err)
+	}
+	gotHeights := make([]uint32, 0)
+	for i, block := range got {
+		gotHeights = append(gotHeights, block.Header.Height)
+		if i > 0 && block.Header.PrevBlock != got[i-1].Header.Hash {
+			t.Errorf("GetRange() block %d doesn't link to previous", block.Header.Height)
+		}
+	}
+	if !reflect.DeepEqual(gotHeights, []uint32{1, 2, 3, 4, 5, 6}) {
+		t.Errorf("GetRange() got = %v", gotHeights)
+	}
+	if len(got[3].Transactions) != 0 || len(got[4].Transactions) != 1 {
+		t.Errorf("GetRange() got transactions %d and %d", len(got[3].Transactions), len(got[4].Transactions))
+	}
+
+	// hashes found by the first walk are kept, only the latest block and blocks of range are requested
+	atomic.StoreInt32(&requests, 0)
+	if _, err := client.Block.GetRange(7, 9); err != nil {
+		t.Fatalf("GetRange() error = %v", err)
+	}
+	if got := atomic.LoadInt32(&requests); got != 4 {
+		t.Errorf("GetRange() of kept hashes requested %d blocks, want 4", got)
+	}
+	if _, err := client.Block.GetRange(0, 0); err != nil {
+		t.Fatalf("GetRange() below kept hashes error = %v", err)
+	}
+
+	// the walk stops at the fork point of a new branch, each block of range is requested once
+	if err := srv.Rollback(2); err != nil {
+		t.Fatal(err)
+	}
+	srv.Mine()
+	srv.Mine()
+	srv.Mine()
+	atomic.StoreInt32(&requests, 0)
+	got, err = client.Block.GetRange(0, uint32(srv.Height()))
+	if err != nil {
+		t.Fatalf("GetRange() of new branch error = %v", err)
+	}
+	if got[len(got)-1].Header.Hash != srv.TipHash() {
+		t.Errorf("GetRange() tip got = %s, want %s", got[len(got)-1].Header.Hash, srv.TipHash())
+	}
+	if want := int32(srv.Height()) + 1; atomic.LoadInt32(&requests) != want {
+		t.Errorf("GetRange() of new branch requested %d blocks, want %d", atomic.LoadInt32(&requests), want)
+	}
+}
+
+func TestBlock_Verify(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
+	client := NewClient(srv.URL)
+	sendTestTx(t, client, Pk, "VLa1hi77ZXD2BSWDD9wQe8vAhejXyS7vBM4", 1000)
+	blockHash := srv.Mine()
+	producer := srv.ProducerKey()
+	untrusted, _ := crypto.GenerateHD()
+	untrustedKey, _ := hex.DecodeString(untrusted.PublicKey())
+
+	tests := []struct {
+		name    string
+		modify  func(block *BlockResponse)
+		trusted [][]byte
+		wantErr bool
+	}{
+		{name: "Without trusted keys", modify: func(block *BlockResponse) {}, wantErr: true},
+		{name: "Trusted producer", modify: func(block *BlockResponse) {}, trusted: [][]byte{producer}, wantErr: false},
+		{name: "Untrusted producer", modify: func(block *BlockResponse) {}, trusted: [][]byte{untrustedKey}, wantErr: true},
+		{
+			name:    "Changed hash",
+			trusted: [][]byte{producer},
+			modify:  func(block *BlockResponse) { block.Header.Hash[0]++ },
+			wantErr: true,
+		},
+		{
+			name:    "Changed signature",
+			trusted: [][]byte{producer},
+			modify:  func(block *BlockResponse) { block.Header.Script = block.Header.Script[2:] + "00" },
+			wantErr: true,
+		},
+		{
+			name:    "Another advice",
+			trusted: [][]byte{producer},
+			modify:  func(block *BlockResponse) { block.Advices[0].PublicKey = untrusted.PublicKey() },
+			wantErr: true,
+		},
+		{
+			name:    "Missing advice",
+			trusted: [][]byte{producer},
+			modify:  func(block *BlockResponse) { block.Advices = nil },
+			wantErr: true,
+		},
+		{
+			name:    "Missing transaction",
+			trusted: [][]byte{producer},
+			modify:  func(block *BlockResponse) { block.Transactions = block.Transactions[:0] },
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			block, err := client.Block.GetByHash(blockHash)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.modify(block)
+			got, err := block.Verify(tt.trusted)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, producer) {
+				t.Errorf("Verify() producer = %x, want %x", got, producer)
+			}
+		})
+	}
+
+	block, _ := client.Block.GetByHash(blockHash)
+	if got, err := block.VerifyUntrusted(); err != nil || !reflect.DeepEqual(got, producer) {
+		t.Errorf("VerifyUntrusted() producer = %x, error = %v", got, err)
+	}
+	block.Header.Hash[0]++
+	if _, err := block.VerifyUntrusted(); err == nil {
+		t.Errorf("VerifyUntrusted() of changed block must fail")
+	}
+}
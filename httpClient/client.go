@@ -1,10 +1,9 @@
 package httpClient
 
 import (
-	"encoding/json"
 	"github.com/go-errors/errors"
+	"github.com/velas/GoVelas/crypto"
 	"github.com/velas/GoVelas/httpClient/response"
-	"gopkg.in/resty.v1"
 )
 
 // Main structure for requesting to node
@@ -14,32 +13,36 @@ type Client struct {
 	Tx          *Tx
 	Block       *Block
 	bk          *baseClient
+	history     historyLists // hash lists kept for cursors of GetHistory
 }
 
 // Create node client
 func NewClient(baseAddress string) *Client {
 	bk := newBaseClient(baseAddress)
+	tx := newTxClient(bk)
 	return &Client{
 		baseAddress: baseAddress,
 		bk:          newBaseClient(baseAddress),
 		Wallet:      newWalletClient(bk),
-		Tx:          newTxClient(bk),
-		Block:       newBlockClient(bk),
+		Tx:          tx,
+		Block:       newBlockClient(bk, tx),
 	}
 }
 
 // Method for get status of node
 func (cl *Client) NodeInfo() (*response.Node, error) {
-	resp, err := resty.
-		R().
-		Get(cl.baseAddress + "/api/v1/info")
+	return cl.bk.nodeInfo()
+}
+
+// IsFinal check that transaction can be included into the next block, LockTime is compared with height of the next
+// block or with timestamp of the last block. The result is advisory only, see crypto.Tx.IsFinal
+func (cl *Client) IsFinal(tx crypto.Tx) (bool, error) {
+	latest, err := cl.Block.GetLatest()
 	if err != nil {
-		return nil, errors.New(err)
+		return false, err
 	}
-	body, err := cl.bk.ReadResponse(resp)
-	result := response.Node{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, errors.New(err)
+	if latest.Header == nil {
+		return false, errors.Errorf("block without header")
 	}
-	return &result, nil
+	return tx.IsFinal(latest.Header.Height+1, latest.Header.Timestamp), nil
 }
@@ -1,10 +1,70 @@
 package httpClient
 
 import (
+	"github.com/velas/GoVelas/crypto"
+	"github.com/velas/GoVelas/httpClient/velastest"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 )
 
+// newTestServer start fake node with funded wallets of Pk and Pk2
+func newTestServer(t *testing.T) *velastest.Server {
+	srv, err := velastest.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	funds := []struct {
+		privateKey string
+		amount     uint64
+	}{
+		{privateKey: Pk, amount: 100000000},
+		{privateKey: Pk, amount: 200000000},
+		{privateKey: Pk2, amount: 10000000000000},
+	}
+	for _, fund := range funds {
+		hd, err := crypto.HDFromPrivateKeyHex(fund.privateKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wallet, err := hd.ToWallet()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := srv.Fund(wallet.Base58Address, fund.amount); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return srv
+}
+
+// buildTestTx create transaction from the first unspent of private key wallet
+func buildTestTx(t *testing.T, client *Client, privateKey string, to string, amount crypto.Amount) *crypto.Tx {
+	hd, _ := crypto.HDFromPrivateKeyHex(privateKey)
+	wallet, _ := hd.ToWallet()
+	unspents, err := client.Wallet.GetUnspent(wallet.Base58Address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := crypto.NewTransaction(unspents[:1], amount, *hd, wallet.Base58Address, to, 1000000, crypto.NodeID{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tx
+}
+
+// sendTestTx publish transaction from private key wallet and return its hash
+func sendTestTx(t *testing.T, client *Client, privateKey string, to string, amount crypto.Amount) crypto.Hash {
+	tx := buildTestTx(t, client, privateKey, to, amount)
+	if err := client.Tx.Publish(*tx); err != nil {
+		t.Fatal(err)
+	}
+	return tx.Hash
+}
+
 func TestClient_NodeInfo(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
 	type fields struct {
 		baseAddress string
 	}
@@ -15,7 +75,7 @@ func TestClient_NodeInfo(t *testing.T) {
 	}{
 		{
 			name:    "Normal test",
-			fields:  fields{baseAddress: Url},
+			fields:  fields{baseAddress: srv.URL},
 			wantErr: false,
 		},
 	}
@@ -37,3 +97,54 @@ func TestClient_NodeInfo(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_NodeInfo_EmptyHash(t *testing.T) {
+	// node without blocks returns empty current hash
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"blockchain":{"height":0,"current_hash":"","current_epoch":""},"is_sync":false}`))
+	}))
+	defer srv.Close()
+	client := NewClient(srv.URL)
+	got, err := client.NodeInfo()
+	if err != nil {
+		t.Fatalf("NodeInfo() error = %v", err)
+	}
+	if !got.Blockchain.CurrentHash.IsEmpty() {
+		t.Errorf("NodeInfo() current hash = %s, want empty", got.Blockchain.CurrentHash)
+	}
+	if _, err := client.Block.GetLatest(); err == nil {
+		t.Errorf("GetLatest() without current hash must fail")
+	}
+}
+
+func TestClient_IsFinal(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
+	client := NewClient(srv.URL)
+	hd, _ := crypto.HDFromPrivateKeyHex(Pk)
+	wallet, _ := hd.ToWallet()
+	unspents, _ := client.Wallet.GetUnspent(wallet.Base58Address)
+	receivers := []crypto.Receiver{{Wallet: "VLa1hi77ZXD2BSWDD9wQe8vAhejXyS7vBM4", Amount: 1000}}
+
+	// tip is at height 3, transaction is allowed from height 5
+	tx, err := crypto.NewTransactionWithOptions(unspents[:1], *hd, wallet.Base58Address, receivers, 1000000,
+		crypto.TxOptions{LockTime: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for height := 3; height <= 4; height++ {
+		final, err := client.IsFinal(*tx)
+		if err != nil {
+			t.Fatalf("IsFinal() error = %v", err)
+		}
+		wantFinal := height == 4
+		if final != wantFinal {
+			t.Errorf("IsFinal() at height %d = %v, want %v", height, final, wantFinal)
+		}
+		if err := client.Tx.Validate(*tx); (err == nil) != wantFinal {
+			t.Errorf("Validate() at height %d error = %v, want final %v", height, err, wantFinal)
+		}
+		srv.Mine()
+	}
+}
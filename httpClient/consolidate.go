@@ -0,0 +1,61 @@
+package httpClient
+
+import (
+	"context"
+	"github.com/velas/GoVelas/crypto"
+)
+
+// Default maximum count of inputs in consolidation transaction
+const DefaultConsolidationMaxInputs = 100
+
+// Options of consolidation
+type ConsolidateOptions struct {
+	MaxInputs     int                               // DefaultConsolidationMaxInputs if zero
+	FeeRates      *crypto.FeeRates                  // rates for commission, crypto.DefaultFeeRates if nil
+	Unspents      []crypto.TransactionInputOutpoint // outputs for consolidation, unspents of wallet are requested if nil
+	Confirmations uint32                            // wait confirmations of every transaction before next one, if not zero
+}
+
+// PlanConsolidation request unspents of wallet and group them into self-transfer transactions, nothing is published
+func (cl *Client) PlanConsolidation(key crypto.HD, opts ConsolidateOptions) ([]crypto.ConsolidationBatch, error) {
+	maxInputs := opts.MaxInputs
+	if maxInputs == 0 {
+		maxInputs = DefaultConsolidationMaxInputs
+	}
+	rates := crypto.DefaultFeeRates
+	if opts.FeeRates != nil {
+		rates = *opts.FeeRates
+	}
+	unspents := opts.Unspents
+	if unspents == nil {
+		wallet, err := key.ToWallet()
+		if err != nil {
+			return nil, err
+		}
+		unspents, err = cl.Wallet.GetUnspent(wallet.Base58Address)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return crypto.PlanConsolidation(unspents, key, maxInputs, rates)
+}
+
+// Consolidate plan consolidation of wallet and publish transactions one by one. On error results of already published
+// transactions are returned with the error
+func (cl *Client) Consolidate(ctx context.Context, key crypto.HD, opts ConsolidateOptions) ([]*SendResult, error) {
+	batches, err := cl.PlanConsolidation(key, opts)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*SendResult, 0, len(batches))
+	for _, batch := range batches {
+		result, err := cl.publishTx(ctx, batch.Tx, batch.Unspents, opts.Confirmations)
+		if result != nil {
+			results = append(results, result)
+		}
+		if err != nil {
+			return results, err
+		}
+	}
+	return results, nil
+}
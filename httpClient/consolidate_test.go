@@ -0,0 +1,47 @@
+package httpClient
+
+import (
+	"context"
+	"github.com/velas/GoVelas/crypto"
+	"testing"
+)
+
+func TestClient_Consolidate(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
+	client := NewClient(srv.URL)
+
+	hd, _ := crypto.GenerateHD()
+	wallet, _ := hd.ToWallet()
+	for i := 0; i < 5; i++ {
+		if _, err := srv.Fund(wallet.Base58Address, 2000000); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	opts := ConsolidateOptions{MaxInputs: 2}
+	batches, err := client.PlanConsolidation(*hd, opts)
+	if err != nil {
+		t.Fatalf("PlanConsolidation() error = %v", err)
+	}
+	if len(batches) != 2 {
+		t.Fatalf("PlanConsolidation() got %d batches, want 2", len(batches))
+	}
+
+	results, err := client.Consolidate(context.Background(), *hd, opts)
+	if err != nil {
+		t.Fatalf("Consolidate() error = %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Consolidate() got %d results, want 2", len(results))
+	}
+	srv.Mine()
+	unspents, _ := client.Wallet.GetUnspent(wallet.Base58Address)
+	if len(unspents) != 3 {
+		t.Errorf("got %d unspents after consolidation, want 3", len(unspents))
+	}
+	balance, _ := client.Wallet.GetBalance(wallet.Base58Address)
+	if want := crypto.Amount(10000000 - 2*crypto.DefaultFeeRates.Min); balance != want {
+		t.Errorf("balance after consolidation = %d, want %d", balance, want)
+	}
+}
@@ -0,0 +1,49 @@
+package httpClient
+
+import (
+	"github.com/go-errors/errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// writeFile write data to temporary file and rename it to path, so file is never partially written
+func writeFile(path string, data []byte) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return errors.New(err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return errors.New(err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return errors.New(err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return errors.New(err)
+	}
+	return nil
+}
+
+// appendFile truncate file at offset and write data after it, file is created if it doesn't exist
+func appendFile(path string, offset int64, data []byte) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE, 0600)
+	if err != nil {
+		return errors.New(err)
+	}
+	if err := file.Truncate(offset); err != nil {
+		_ = file.Close()
+		return errors.New(err)
+	}
+	if _, err := file.WriteAt(data, offset); err != nil {
+		_ = file.Close()
+		return errors.New(err)
+	}
+	if err := file.Close(); err != nil {
+		return errors.New(err)
+	}
+	return nil
+}
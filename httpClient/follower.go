@@ -0,0 +1,283 @@
+package httpClient
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-errors/errors"
+	"github.com/velas/GoVelas/crypto"
+	"io/ioutil"
+	"os"
+	"time"
+)
+
+// Default interval between requests of node info, when follower reached the tip of chain
+const DefaultFollowerPollInterval = 5 * time.Second
+
+// Default maximum count of blocks, which can be rolled back on reorganization
+const DefaultMaxReorgDepth = 100
+
+// Error returned when the fork point is deeper than MaxReorgDepth blocks
+var ErrReorgTooDeep = errors.New("reorganization is deeper than maximum depth")
+
+// Type of follower event
+type FollowerEventType int
+
+const (
+	BlockConnected FollowerEventType = iota // new block is added to the chain
+	BlockRollback                           // blocks are removed from the chain by reorganization
+)
+
+// Event emitted by chain follower
+type FollowerEvent struct {
+	Type     FollowerEventType
+	Block    *BlockResponse   // connected block, only for BlockConnected
+	Orphaned []*BlockResponse // removed blocks from the old tip to the fork point, only for BlockRollback
+}
+
+// Last processed block of follower
+type Checkpoint struct {
+	Height uint32      `json:"height"`
+	Hash   crypto.Hash `json:"hash"`
+}
+
+// Storage for follower checkpoint
+type CheckpointStore interface {
+	Load() (*Checkpoint, error) // return nil without error if checkpoint is not saved yet
+	Save(checkpoint Checkpoint) error
+}
+
+// Checkpoint store in json file
+type FileCheckpointStore struct {
+	Path string
+}
+
+// Load checkpoint from file, missing file is not an error
+func (fs *FileCheckpointStore) Load() (*Checkpoint, error) {
+	data, err := ioutil.ReadFile(fs.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.New(err)
+	}
+	checkpoint := Checkpoint{}
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return nil, errors.New(err)
+	}
+	return &checkpoint, nil
+}
+
+// Save checkpoint to temporary file and rename it, so file always contains full checkpoint
+func (fs *FileCheckpointStore) Save(checkpoint Checkpoint) error {
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return errors.New(err)
+	}
+	return writeFile(fs.Path, data)
+}
+
+// Options of chain follower. Follower starts from saved checkpoint if it exists, else from StartHash if it is set,
+// else from StartHeight. Start block is emitted as the first event, block of checkpoint is not emitted again
+type FollowerOptions struct {
+	StartHeight   uint32
+	StartHash     crypto.Hash
+	PollInterval  time.Duration   // DefaultFollowerPollInterval if zero
+	MaxReorgDepth int             // DefaultMaxReorgDepth if zero
+	Checkpoints   CheckpointStore // optional
+	Buffer        int             // size of events channel
+}
+
+// Chain follower, emit blocks in order of heights and rollbacks on reorganizations
+type Follower struct {
+	blk    *Block
+	bk     *baseClient
+	opts   FollowerOptions
+	events chan FollowerEvent
+	chain  []*BlockResponse // last emitted blocks, the last one is the tip
+}
+
+// Create chain follower, call Run for start following
+func (cl *Client) NewFollower(opts FollowerOptions) *Follower {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultFollowerPollInterval
+	}
+	if opts.MaxReorgDepth <= 0 {
+		opts.MaxReorgDepth = DefaultMaxReorgDepth
+	}
+	return &Follower{
+		blk:    cl.Block,
+		bk:     cl.bk,
+		opts:   opts,
+		events: make(chan FollowerEvent, opts.Buffer),
+	}
+}
+
+// Events return channel of follower events, channel is closed when Run returns
+func (f *Follower) Events() <-chan FollowerEvent {
+	return f.events
+}
+
+// Run follow the chain until context is done or error occurs
+func (f *Follower) Run(ctx context.Context) error {
+	defer close(f.events)
+
+	if err := f.start(ctx); err != nil {
+		return err
+	}
+	for {
+		info, err := f.bk.nodeInfo()
+		if err != nil {
+			return err
+		}
+		if info.Blockchain == nil {
+			return errors.Errorf("node info doesn't contain blockchain state")
+		}
+		tip := f.chain[len(f.chain)-1]
+		if info.Blockchain.Height > int(tip.Header.Height) {
+			to := uint32(info.Blockchain.Height)
+			if to-tip.Header.Height > DefaultBlockRangeSize {
+				to = tip.Header.Height + DefaultBlockRangeSize
+			}
+			next, err := f.blk.GetRange(tip.Header.Height+1, to)
+			if err != nil {
+				return err
+			}
+			if next[0].Header.PrevBlock != tip.Header.Hash {
+				if err := f.rollback(ctx, info.Blockchain.Height); err != nil {
+					return err
+				}
+				continue
+			}
+			// blocks of range are linked to each other by GetRange
+			for _, block := range next {
+				if err := f.connect(ctx, block); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if info.Blockchain.CurrentHash != tip.Header.Hash {
+			// another tip on the same or lower height
+			if err := f.rollback(ctx, info.Blockchain.Height); err != nil {
+				return err
+			}
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(f.opts.PollInterval):
+		}
+	}
+}
+
+// start load checkpoint or first block
+func (f *Follower) start(ctx context.Context) error {
+	if f.opts.Checkpoints != nil {
+		checkpoint, err := f.opts.Checkpoints.Load()
+		if err != nil {
+			return err
+		}
+		if checkpoint != nil {
+			block, err := f.blk.GetByHash(checkpoint.Hash)
+			if err != nil {
+				return err
+			}
+			f.chain = []*BlockResponse{block}
+			return nil
+		}
+	}
+
+	var block *BlockResponse
+	var err error
+	if !f.opts.StartHash.IsEmpty() {
+		block, err = f.blk.GetByHash(f.opts.StartHash)
+	} else {
+		block, err = f.blk.GetByHeight(f.opts.StartHeight)
+	}
+	if err != nil {
+		return err
+	}
+	return f.connect(ctx, block)
+}
+
+// connect add block to the chain, emit it and save checkpoint
+func (f *Follower) connect(ctx context.Context, block *BlockResponse) error {
+	if err := f.emit(ctx, FollowerEvent{Type: BlockConnected, Block: block}); err != nil {
+		return err
+	}
+	f.chain = append(f.chain, block)
+	if len(f.chain) > f.opts.MaxReorgDepth {
+		f.chain = f.chain[len(f.chain)-f.opts.MaxReorgDepth:]
+	}
+	return f.save()
+}
+
+// rollback remove blocks from the tip, while they are not in the main chain of node, and emit them. Hashes of main
+// chain are requested once for MaxReorgDepth blocks below the tip. Nothing is emitted, if the tip is in the main chain
+func (f *Follower) rollback(ctx context.Context, nodeHeight int) error {
+	to := f.chain[len(f.chain)-1].Header.Height
+	if nodeHeight < int(to) {
+		to = uint32(nodeHeight)
+	}
+	from := uint32(0)
+	if to > uint32(f.opts.MaxReorgDepth) {
+		from = to - uint32(f.opts.MaxReorgDepth)
+	}
+	main, err := f.blk.GetHashesByRange(from, to)
+	if err != nil {
+		return err
+	}
+
+	orphaned := make([]*BlockResponse, 0)
+	for {
+		if len(f.chain) == 0 {
+			// blocks before checkpoint or old blocks are not kept, load them from node
+			parent, err := f.blk.GetByHash(orphaned[len(orphaned)-1].Header.PrevBlock)
+			if err != nil {
+				return err
+			}
+			f.chain = []*BlockResponse{parent}
+		}
+		top := f.chain[len(f.chain)-1]
+		height := top.Header.Height
+		if height < from {
+			return ErrReorgTooDeep
+		}
+		if height <= to && main[height-from] == top.Header.Hash {
+			break
+		}
+		orphaned = append(orphaned, top)
+		f.chain = f.chain[:len(f.chain)-1]
+		if len(orphaned) > f.opts.MaxReorgDepth {
+			return ErrReorgTooDeep
+		}
+	}
+	if len(orphaned) == 0 {
+		return nil
+	}
+	if err := f.emit(ctx, FollowerEvent{Type: BlockRollback, Orphaned: orphaned}); err != nil {
+		return err
+	}
+	return f.save()
+}
+
+// emit send event, while context is not done
+func (f *Follower) emit(ctx context.Context, event FollowerEvent) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case f.events <- event:
+		return nil
+	}
+}
+
+// save checkpoint of the tip
+func (f *Follower) save() error {
+	if f.opts.Checkpoints == nil {
+		return nil
+	}
+	tip := f.chain[len(f.chain)-1]
+	return f.opts.Checkpoints.Save(Checkpoint{Height: tip.Header.Height, Hash: tip.Header.Hash})
+}
@@ -0,0 +1,145 @@
+package httpClient
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// nextEvent wait event of follower with timeout
+func nextEvent(t *testing.T, f *Follower) FollowerEvent {
+	select {
+	case event, ok := <-f.Events():
+		if !ok {
+			t.Fatal("events channel is closed")
+		}
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout of follower event")
+	}
+	return FollowerEvent{}
+}
+
+// wantConnected check that next event is connected block with height
+func wantConnected(t *testing.T, f *Follower, height uint32) *BlockResponse {
+	event := nextEvent(t, f)
+	if event.Type != BlockConnected || event.Block.Header.Height != height {
+		t.Fatalf("event got = %+v, want connected block %d", event, height)
+	}
+	return event.Block
+}
+
+func TestFollower_Run(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
+	client := NewClient(srv.URL)
+	f := client.NewFollower(FollowerOptions{StartHeight: 1, PollInterval: 10 * time.Millisecond})
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() { errs <- f.Run(ctx) }()
+
+	for height := uint32(1); height <= 3; height++ {
+		wantConnected(t, f, height)
+	}
+
+	srv.Mine()
+	wantConnected(t, f, 4)
+
+	// replace blocks 3 and 4 by three new blocks
+	orphanedTip := srv.TipHash()
+	if err := srv.Rollback(2); err != nil {
+		t.Fatal(err)
+	}
+	srv.Mine()
+	srv.Mine()
+	srv.Mine()
+
+	event := nextEvent(t, f)
+	if event.Type != BlockRollback || len(event.Orphaned) != 2 {
+		t.Fatalf("event got = %+v, want rollback of 2 blocks", event)
+	}
+	if event.Orphaned[0].Header.Hash != orphanedTip || event.Orphaned[1].Header.Height != 3 {
+		t.Errorf("rollback got = %s at %d, want tip first", event.Orphaned[0].Header.Hash, event.Orphaned[0].Header.Height)
+	}
+	for height := uint32(3); height <= 5; height++ {
+		wantConnected(t, f, height)
+	}
+
+	cancel()
+	if err := <-errs; err != context.Canceled {
+		t.Errorf("Run() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestFollower_Checkpoint(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
+	dir, err := ioutil.TempDir("", "follower")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store := &FileCheckpointStore{Path: filepath.Join(dir, "checkpoint.json")}
+	client := NewClient(srv.URL)
+
+	// first run saves checkpoint of block 3
+	f := client.NewFollower(FollowerOptions{StartHeight: 0, PollInterval: 10 * time.Millisecond, Checkpoints: store})
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() { errs <- f.Run(ctx) }()
+	for height := uint32(0); height <= 3; height++ {
+		wantConnected(t, f, height)
+	}
+	cancel()
+	<-errs
+	for range f.Events() {
+	}
+
+	checkpoint, err := store.Load()
+	if err != nil || checkpoint == nil || checkpoint.Height != 3 || checkpoint.Hash != srv.TipHash() {
+		t.Fatalf("Load() got = %+v, error = %v", checkpoint, err)
+	}
+
+	// block 3 is orphaned while follower is stopped
+	if err := srv.Rollback(1); err != nil {
+		t.Fatal(err)
+	}
+	srv.Mine()
+	srv.Mine()
+
+	f = client.NewFollower(FollowerOptions{StartHeight: 0, PollInterval: 10 * time.Millisecond, Checkpoints: store})
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	go func() { errs <- f.Run(ctx) }()
+	event := nextEvent(t, f)
+	if event.Type != BlockRollback || len(event.Orphaned) != 1 || event.Orphaned[0].Header.Hash != checkpoint.Hash {
+		t.Fatalf("event got = %+v, want rollback of checkpoint block", event)
+	}
+	wantConnected(t, f, 3)
+	wantConnected(t, f, 4)
+}
+
+func TestFollower_RollbackInMainChain(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
+	f := NewClient(srv.URL).NewFollower(FollowerOptions{StartHash: srv.TipHash(), Buffer: 2})
+	ctx := context.Background()
+	if err := f.start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	wantConnected(t, f, uint32(srv.Height()))
+
+	// fork point is the tip, so nothing is orphaned
+	if err := f.rollback(ctx, srv.Height()); err != nil {
+		t.Fatalf("rollback() error = %v", err)
+	}
+	if len(f.Events()) != 0 {
+		t.Errorf("rollback() of tip in main chain emitted %+v", <-f.Events())
+	}
+	if f.chain[len(f.chain)-1].Header.Hash != srv.TipHash() {
+		t.Errorf("rollback() removed the tip")
+	}
+}
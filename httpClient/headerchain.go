@@ -0,0 +1,431 @@
+package httpClient
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/go-errors/errors"
+	"github.com/velas/GoVelas/crypto"
+	"io"
+	"os"
+	"sync"
+)
+
+// Storage for headers of header chain
+type HeaderStore interface {
+	Load() ([]*Header, error) // return nil without error if headers are not saved yet
+	// Save headers from height of the first one, saved headers at this height and above are replaced. The first
+	// header must follow saved headers or replace one of them
+	Save(headers []*Header) error
+}
+
+// Header store in file, each header is a json line. New headers are appended to the file, the file is truncated
+// only when saved headers are replaced by reorganization
+type FileHeaderStore struct {
+	Path    string
+	mu      sync.Mutex
+	first   uint32  // height of the first saved header
+	offsets []int64 // offsets of saved headers in file and offset of the end, nil if file is not read yet
+}
+
+// Load headers from file, missing file is not an error. Not fully written last line is skipped, it is replaced by
+// the next Save
+func (fs *FileHeaderStore) Load() ([]*Header, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	return fs.load()
+}
+
+// load read headers from file and offsets of them
+func (fs *FileHeaderStore) load() ([]*Header, error) {
+	file, err := os.Open(fs.Path)
+	if os.IsNotExist(err) {
+		fs.first, fs.offsets = 0, []int64{0}
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.New(err)
+	}
+	defer file.Close()
+
+	headers := make([]*Header, 0)
+	offsets := []int64{0}
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.New(err)
+		}
+		header := &Header{}
+		if err := json.Unmarshal(line, header); err != nil {
+			return nil, errors.New(err)
+		}
+		if len(headers) > 0 && header.Height != headers[len(headers)-1].Height+1 {
+			return nil, errors.Errorf("saved header %s has height %d, want %d", header.Hash, header.Height, headers[len(headers)-1].Height+1)
+		}
+		headers = append(headers, header)
+		offsets = append(offsets, offsets[len(offsets)-1]+int64(len(line)))
+	}
+	fs.first, fs.offsets = 0, offsets
+	if len(headers) > 0 {
+		fs.first = headers[0].Height
+	}
+	return headers, nil
+}
+
+// Save append headers to file, saved headers from height of the first one are cut off before
+func (fs *FileHeaderStore) Save(headers []*Header) error {
+	if len(headers) == 0 {
+		return nil
+	}
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	if fs.offsets == nil {
+		if _, err := fs.load(); err != nil {
+			return err
+		}
+	}
+	count := len(fs.offsets) - 1
+	first := fs.first
+	if count == 0 {
+		first = headers[0].Height
+	}
+	height := headers[0].Height
+	if height < first || int(height-first) > count {
+		return errors.Errorf("header at height %d doesn't follow saved headers", height)
+	}
+	index := int(height - first)
+
+	data := &bytes.Buffer{}
+	offsets := append(make([]int64, 0, index+1+len(headers)), fs.offsets[:index+1]...)
+	for _, header := range headers {
+		line, err := json.Marshal(header)
+		if err != nil {
+			return errors.New(err)
+		}
+		data.Write(line)
+		data.WriteByte('\n')
+		offsets = append(offsets, offsets[len(offsets)-1]+int64(len(line))+1)
+	}
+	if err := appendFile(fs.Path, fs.offsets[index], data.Bytes()); err != nil {
+		// size of file is unknown, it is read again by the next Save
+		fs.offsets = nil
+		return err
+	}
+	fs.first, fs.offsets = first, offsets
+	return nil
+}
+
+// Options of header chain. Chain starts from StartHash if it is set, else from StartHeight, all next headers are
+// linked to the start header. Hash of every header must be signed by one of Trusted producers, so node can't make up
+// blocks. Other fields of header aren't checked against its hash, see checkHeader
+type HeaderChainOptions struct {
+	StartHeight   uint32
+	StartHash     crypto.Hash
+	MaxReorgDepth int         // DefaultMaxReorgDepth if zero
+	Store         HeaderStore // optional, headers are kept only in memory without it
+	Trusted       [][]byte    // public keys of producers, required
+}
+
+// Header chain of light client. It downloads headers from nodes, checks signatures, heights and links, keeps the
+// best chain and answers queries about main chain without requests to node
+type HeaderChain struct {
+	opts    HeaderChainOptions
+	syncMu  sync.Mutex // only one Sync at once
+	mu      sync.RWMutex
+	headers []*Header // main chain from the start header to the tip
+	heights map[crypto.Hash]uint32
+}
+
+// Create header chain and load saved headers from store
+func NewHeaderChain(opts HeaderChainOptions) (*HeaderChain, error) {
+	if len(opts.Trusted) == 0 {
+		return nil, errors.Errorf("trusted producer keys are required")
+	}
+	if opts.MaxReorgDepth <= 0 {
+		opts.MaxReorgDepth = DefaultMaxReorgDepth
+	}
+	hc := &HeaderChain{
+		opts:    opts,
+		headers: make([]*Header, 0),
+		heights: make(map[crypto.Hash]uint32),
+	}
+	if opts.Store == nil {
+		return hc, nil
+	}
+	headers, err := opts.Store.Load()
+	if err != nil {
+		return nil, err
+	}
+	for i, header := range headers {
+		if i == 0 {
+			if err := hc.checkStart(header); err != nil {
+				return nil, err
+			}
+		} else if err := hc.checkNext(headers[i-1], header); err != nil {
+			return nil, err
+		}
+		hc.heights[header.Hash] = header.Height
+	}
+	hc.headers = headers
+	return hc, nil
+}
+
+// Tip return the last header of main chain, nil if chain is empty
+func (hc *HeaderChain) Tip() *Header {
+	hc.mu.RLock()
+	defer hc.mu.RUnlock()
+	if len(hc.headers) == 0 {
+		return nil
+	}
+	return hc.headers[len(hc.headers)-1]
+}
+
+// HeaderByHeight return header of main chain at height
+func (hc *HeaderChain) HeaderByHeight(height uint32) (*Header, bool) {
+	hc.mu.RLock()
+	defer hc.mu.RUnlock()
+	if len(hc.headers) == 0 || height < hc.headers[0].Height {
+		return nil, false
+	}
+	index := int(height - hc.headers[0].Height)
+	if index >= len(hc.headers) {
+		return nil, false
+	}
+	return hc.headers[index], true
+}
+
+// HeaderByHash return header of main chain by its hash
+func (hc *HeaderChain) HeaderByHash(hash crypto.Hash) (*Header, bool) {
+	hc.mu.RLock()
+	height, ok := hc.heights[hash]
+	hc.mu.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	return hc.HeaderByHeight(height)
+}
+
+// Contains check that block with hash is in main chain at height
+func (hc *HeaderChain) Contains(hash crypto.Hash, height uint32) bool {
+	header, ok := hc.HeaderByHeight(height)
+	return ok && header.Hash == hash
+}
+
+// Sync download new headers from node by ranges of DefaultBlockRangeSize blocks. If node has another branch, the
+// chain switches to it, when the branch is not shorter than the current one and the fork point is not deeper than
+// MaxReorgDepth, a shorter branch is ignored. Every downloaded range is saved, so progress isn't lost on error. Returns
+// true if the tip is changed
+func (hc *HeaderChain) Sync(ctx context.Context, cl *Client) (bool, error) {
+	hc.syncMu.Lock()
+	defer hc.syncMu.Unlock()
+
+	info, err := cl.NodeInfo()
+	if err != nil {
+		return false, err
+	}
+	if info.Blockchain == nil {
+		return false, errors.Errorf("node info doesn't contain blockchain state")
+	}
+	nodeHeight := uint32(info.Blockchain.Height)
+
+	hc.mu.RLock()
+	headers := hc.headers
+	hc.mu.RUnlock()
+
+	changed := false
+	if len(headers) == 0 {
+		start, err := hc.startHeader(cl)
+		if err != nil {
+			return false, err
+		}
+		if err := hc.update([]*Header{start}, 0); err != nil {
+			return false, err
+		}
+		headers = []*Header{start}
+		changed = true
+	}
+	if tip := headers[len(headers)-1]; tip.Height == nodeHeight && info.Blockchain.CurrentHash != tip.Hash {
+		// node has another branch of the same height
+		nodeTip, err := cl.Block.GetHeader(info.Blockchain.CurrentHash)
+		if err != nil {
+			return changed, err
+		}
+		if nodeTip.Height != nodeHeight {
+			return changed, errors.Errorf("header %s has height %d, want %d", nodeTip.Hash, nodeTip.Height, nodeHeight)
+		}
+		fork, missing, err := hc.findFork(ctx, cl, headers, nodeTip)
+		if err != nil {
+			return changed, err
+		}
+		branch, err := hc.switchBranch(headers, fork, append(missing, nodeTip))
+		if branch != nil {
+			headers = branch
+			changed = true
+		}
+		if err != nil {
+			return changed, err
+		}
+	}
+	for headers[len(headers)-1].Height < nodeHeight {
+		if err := ctx.Err(); err != nil {
+			return changed, err
+		}
+		tip := headers[len(headers)-1]
+		to := nodeHeight
+		if to-tip.Height > DefaultBlockRangeSize {
+			to = tip.Height + DefaultBlockRangeSize
+		}
+		next, err := cl.Block.GetHeadersByRange(tip.Height+1, to)
+		if err != nil {
+			return changed, err
+		}
+
+		fork := len(headers) - 1
+		if next[0].PrevBlock != tip.Hash {
+			var missing []*Header
+			fork, missing, err = hc.findFork(ctx, cl, headers, next[0])
+			if err != nil {
+				return changed, err
+			}
+			next = append(missing, next...)
+		}
+		branch, err := hc.switchBranch(headers, fork, next)
+		if branch != nil {
+			headers = branch
+			changed = true
+		}
+		if err != nil {
+			return changed, err
+		}
+	}
+	return changed, nil
+}
+
+// switchBranch check headers of branch after the fork point at index fork, replace main chain with the branch and
+// save it. The new main chain is returned, if main chain is replaced, even when saving fails
+func (hc *HeaderChain) switchBranch(headers []*Header, fork int, next []*Header) ([]*Header, error) {
+	branch := headers[:fork+1]
+	if fork < len(headers)-1 {
+		// replaced headers can be read from the current chain, so branch is copied on append
+		branch = headers[: fork+1 : fork+1]
+	}
+	for _, header := range next {
+		if err := hc.checkNext(branch[len(branch)-1], header); err != nil {
+			return nil, err
+		}
+		branch = append(branch, header)
+	}
+	return branch, hc.update(branch, fork+1)
+}
+
+// startHeader request the first header of chain
+func (hc *HeaderChain) startHeader(cl *Client) (*Header, error) {
+	var header *Header
+	var err error
+	if !hc.opts.StartHash.IsEmpty() {
+		header, err = cl.Block.GetHeader(hc.opts.StartHash)
+	} else {
+		header, err = cl.Block.GetHeaderByHeight(hc.opts.StartHeight)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := hc.checkStart(header); err != nil {
+		return nil, err
+	}
+	return header, nil
+}
+
+// findFork walk back by links from header of another branch to headers of chain. Returns index of the last common
+// header and headers of the branch between it and header. Headers are requested only on reorganization
+func (hc *HeaderChain) findFork(ctx context.Context, cl *Client, headers []*Header, header *Header) (int, []*Header, error) {
+	first := headers[0].Height
+	missing := make([]*Header, 0)
+	for {
+		if err := ctx.Err(); err != nil {
+			return 0, nil, err
+		}
+		if header.Height <= first {
+			return 0, nil, errors.Errorf("start header %s isn't in main chain of node", headers[0].Hash)
+		}
+		index := int(header.Height - 1 - first)
+		if len(headers)-1-index > hc.opts.MaxReorgDepth {
+			return 0, nil, ErrReorgTooDeep
+		}
+		if headers[index].Hash == header.PrevBlock {
+			// headers are found from the fork point down to it
+			for i, j := 0, len(missing)-1; i < j; i, j = i+1, j-1 {
+				missing[i], missing[j] = missing[j], missing[i]
+			}
+			return index, missing, nil
+		}
+		prev, err := cl.Block.GetHeader(header.PrevBlock)
+		if err != nil {
+			return 0, nil, err
+		}
+		if prev.Hash != header.PrevBlock || prev.Height+1 != header.Height {
+			return 0, nil, errors.Errorf("header %s at %d doesn't link to previous %s", header.Hash, header.Height, header.PrevBlock)
+		}
+		missing = append(missing, prev)
+		header = prev
+	}
+}
+
+// checkStart check the first header of chain
+func (hc *HeaderChain) checkStart(header *Header) error {
+	if !hc.opts.StartHash.IsEmpty() && header.Hash != hc.opts.StartHash {
+		return errors.Errorf("start header %s doesn't match %s", header.Hash, hc.opts.StartHash)
+	}
+	if hc.opts.StartHash.IsEmpty() && header.Height != hc.opts.StartHeight {
+		return errors.Errorf("start header height %d doesn't match %d", header.Height, hc.opts.StartHeight)
+	}
+	return hc.checkHeader(header)
+}
+
+// checkNext check that header follows the previous one
+func (hc *HeaderChain) checkNext(prev *Header, header *Header) error {
+	if header.Height != prev.Height+1 {
+		return errors.Errorf("header %s has height %d, want %d", header.Hash, header.Height, prev.Height+1)
+	}
+	if header.PrevBlock != prev.Hash {
+		return errors.Errorf("header %s at %d doesn't link to previous %s", header.Hash, header.Height, prev.Hash)
+	}
+	return hc.checkHeader(header)
+}
+
+// checkHeader check that hash of header is signed by trusted producer. Hash isn't recalculated from header fields,
+// layout of header isn't confirmed against the node implementation
+func (hc *HeaderChain) checkHeader(header *Header) error {
+	decoded, err := header.Decode()
+	if err != nil {
+		return err
+	}
+	for _, key := range hc.opts.Trusted {
+		if decoded.VerifySignature(key) == nil {
+			return nil
+		}
+	}
+	return errors.Errorf("header %s at %d isn't signed by trusted producer", header.Hash, header.Height)
+}
+
+// update replace main chain from index from and save new headers
+func (hc *HeaderChain) update(headers []*Header, from int) error {
+	hc.mu.Lock()
+	for _, header := range hc.headers[from:] {
+		delete(hc.heights, header.Hash)
+	}
+	for _, header := range headers[from:] {
+		hc.heights[header.Hash] = header.Height
+	}
+	hc.headers = headers
+	hc.mu.Unlock()
+	if hc.opts.Store == nil {
+		return nil
+	}
+	return hc.opts.Store.Save(headers[from:])
+}
@@ -0,0 +1,265 @@
+package httpClient
+
+import (
+	"context"
+	"encoding/hex"
+	"github.com/velas/GoVelas/crypto"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHeaderChain_Sync(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
+	dir, err := ioutil.TempDir("", "headerchain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store := &FileHeaderStore{Path: filepath.Join(dir, "headers.json")}
+	client := NewClient(srv.URL)
+	ctx := context.Background()
+
+	hc, err := NewHeaderChain(HeaderChainOptions{Store: store, Trusted: [][]byte{srv.ProducerKey()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hc.Tip() != nil {
+		t.Fatalf("Tip() of empty chain got = %+v", hc.Tip())
+	}
+	if changed, err := hc.Sync(ctx, client); err != nil || !changed {
+		t.Fatalf("Sync() got = %v, error = %v", changed, err)
+	}
+	if changed, err := hc.Sync(ctx, client); err != nil || changed {
+		t.Fatalf("Sync() without new blocks got = %v, error = %v", changed, err)
+	}
+	tip := hc.Tip()
+	if tip.Hash != srv.TipHash() || int(tip.Height) != srv.Height() {
+		t.Fatalf("Tip() got = %s at %d, want %s", tip.Hash, tip.Height, srv.TipHash())
+	}
+	orphaned := tip.Hash
+
+	// replace the tip by two new blocks
+	if err := srv.Rollback(1); err != nil {
+		t.Fatal(err)
+	}
+	srv.Mine()
+	srv.Mine()
+	if changed, err := hc.Sync(ctx, client); err != nil || !changed {
+		t.Fatalf("Sync() of reorganization got = %v, error = %v", changed, err)
+	}
+	if hc.Contains(orphaned, tip.Height) {
+		t.Errorf("Contains() of orphaned block got = true")
+	}
+	if !hc.Contains(srv.TipHash(), uint32(srv.Height())) {
+		t.Errorf("Contains() of tip got = false")
+	}
+	if _, ok := hc.HeaderByHash(orphaned); ok {
+		t.Errorf("HeaderByHash() of orphaned block is found")
+	}
+
+	// node switched to the shorter branch, light client keeps the longer one
+	best := srv.TipHash()
+	if err := srv.Rollback(2); err != nil {
+		t.Fatal(err)
+	}
+	srv.Mine()
+	if changed, err := hc.Sync(ctx, client); err != nil || changed {
+		t.Fatalf("Sync() of shorter branch got = %v, error = %v", changed, err)
+	}
+	if hc.Tip().Hash != best {
+		t.Errorf("Tip() got = %s, want %s", hc.Tip().Hash, best)
+	}
+
+	// branch of node reaches the same height
+	srv.Mine()
+	if changed, err := hc.Sync(ctx, client); err != nil || !changed {
+		t.Fatalf("Sync() of branch with the same height got = %v, error = %v", changed, err)
+	}
+	if hc.Tip().Hash != srv.TipHash() || hc.Contains(best, hc.Tip().Height) {
+		t.Errorf("Tip() got = %s, want %s", hc.Tip().Hash, srv.TipHash())
+	}
+
+	// node extends the chain by more blocks than one range
+	for i := 0; i < DefaultBlockRangeSize+5; i++ {
+		srv.Mine()
+	}
+	if changed, err := hc.Sync(ctx, client); err != nil || !changed {
+		t.Fatalf("Sync() of long branch got = %v, error = %v", changed, err)
+	}
+	if hc.Tip().Hash != srv.TipHash() {
+		t.Errorf("Tip() got = %s, want %s", hc.Tip().Hash, srv.TipHash())
+	}
+
+	// saved chain answers queries without node
+	loaded, err := NewHeaderChain(HeaderChainOptions{Store: &FileHeaderStore{Path: store.Path}, Trusted: [][]byte{srv.ProducerKey()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for height := uint32(0); height <= hc.Tip().Height; height++ {
+		want, _ := hc.HeaderByHeight(height)
+		if !loaded.Contains(want.Hash, height) {
+			t.Errorf("Contains() of loaded chain at %d got = false", height)
+		}
+		if got, ok := loaded.HeaderByHash(want.Hash); !ok || got.Height != height {
+			t.Errorf("HeaderByHash() of loaded chain got = %+v, want height %d", got, height)
+		}
+	}
+	if _, ok := loaded.HeaderByHeight(hc.Tip().Height + 1); ok {
+		t.Errorf("HeaderByHeight() above tip is found")
+	}
+}
+
+func TestHeaderChain_Validation(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
+	client := NewClient(srv.URL)
+	untrusted, _ := crypto.GenerateHD()
+	untrustedKey, _ := hex.DecodeString(untrusted.PublicKey())
+	trusted := [][]byte{srv.ProducerKey()}
+	start, err := client.Block.GetHeaderByHeight(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewHeaderChain(HeaderChainOptions{StartHeight: 1}); err == nil {
+		t.Errorf("NewHeaderChain() without trusted keys must fail")
+	}
+
+	tests := []struct {
+		name    string
+		opts    HeaderChainOptions
+		wantErr bool
+	}{
+		{name: "Start height", opts: HeaderChainOptions{StartHeight: 1, Trusted: trusted}, wantErr: false},
+		{name: "Start hash", opts: HeaderChainOptions{StartHash: start.Hash, Trusted: trusted}, wantErr: false},
+		{name: "Unknown start hash", opts: HeaderChainOptions{StartHash: crypto.DHASH([]byte("unknown")), Trusted: trusted}, wantErr: true},
+		{name: "Start above tip", opts: HeaderChainOptions{StartHeight: 100, Trusted: trusted}, wantErr: true},
+		{name: "Untrusted producer", opts: HeaderChainOptions{Trusted: [][]byte{untrustedKey}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hc, err := NewHeaderChain(tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = hc.Sync(context.Background(), client)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Sync() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (hc.Tip().Hash != srv.TipHash() || !hc.Contains(start.Hash, 1)) {
+				t.Errorf("Sync() tip got = %s, want %s", hc.Tip().Hash, srv.TipHash())
+			}
+		})
+	}
+}
+
+func TestNewHeaderChain_BrokenStore(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
+	headers, err := NewClient(srv.URL).Block.GetHeadersByRange(0, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := ioutil.TempDir("", "headerchain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name    string
+		headers []*Header
+		wantErr bool
+	}{
+		{name: "Linked", headers: headers, wantErr: false},
+		{name: "Gap", headers: []*Header{headers[0], headers[2]}, wantErr: true},
+		{name: "Wrong start", headers: headers[1:], wantErr: true},
+		{name: "Changed header", headers: []*Header{headers[0], {Height: 1, PrevBlock: headers[0].Hash, Hash: headers[1].Hash}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &FileHeaderStore{Path: filepath.Join(dir, tt.name+".json")}
+			if err := store.Save(tt.headers); err != nil {
+				t.Fatal(err)
+			}
+			_, err := NewHeaderChain(HeaderChainOptions{Store: store, Trusted: [][]byte{srv.ProducerKey()}})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewHeaderChain() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFileHeaderStore(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
+	headers, err := NewClient(srv.URL).Block.GetHeadersByRange(0, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := ioutil.TempDir("", "headerchain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "headers.json")
+	store := &FileHeaderStore{Path: path}
+	replaced := &Header{Height: 2, PrevBlock: headers[1].Hash, Hash: crypto.DHASH([]byte("replaced"))}
+
+	steps := []struct {
+		name    string
+		headers []*Header
+		want    []*Header
+		wantErr bool
+	}{
+		{name: "First", headers: headers[:2], want: headers[:2]},
+		{name: "Append", headers: headers[2:], want: headers},
+		{name: "Replace", headers: []*Header{replaced}, want: []*Header{headers[0], headers[1], replaced}},
+		{name: "Gap", headers: []*Header{{Height: 4}}, wantErr: true},
+	}
+	for _, step := range steps {
+		if err := store.Save(step.headers); (err != nil) != step.wantErr {
+			t.Fatalf("%s: Save() error = %v, wantErr %v", step.name, err, step.wantErr)
+		}
+		if step.wantErr {
+			continue
+		}
+		// a new store reads the file, the written store keeps offsets in memory
+		for _, s := range []*FileHeaderStore{store, {Path: path}} {
+			got, err := s.Load()
+			if err != nil {
+				t.Fatalf("%s: Load() error = %v", step.name, err)
+			}
+			if len(got) != len(step.want) {
+				t.Fatalf("%s: Load() got %d headers, want %d", step.name, len(got), len(step.want))
+			}
+			for i := range got {
+				if got[i].Hash != step.want[i].Hash {
+					t.Errorf("%s: Load() header %d got = %s, want %s", step.name, i, got[i].Hash, step.want[i].Hash)
+				}
+			}
+		}
+	}
+
+	// not fully written line is skipped and replaced by the next save
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := file.WriteString(`{"height":3,`); err != nil {
+		t.Fatal(err)
+	}
+	_ = file.Close()
+	store = &FileHeaderStore{Path: path}
+	if got, err := store.Load(); err != nil || len(got) != 3 {
+		t.Fatalf("Load() with partial line got %d headers, error = %v", len(got), err)
+	}
+	if err := store.Save([]*Header{{Height: 3, PrevBlock: replaced.Hash}}); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := (&FileHeaderStore{Path: path}).Load(); err != nil || len(got) != 4 {
+		t.Errorf("Load() after save got %d headers, error = %v", len(got), err)
+	}
+}
@@ -0,0 +1,328 @@
+package httpClient
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/go-errors/errors"
+	"github.com/velas/GoVelas/crypto"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Default count of history entries in one page
+const DefaultHistoryLimit = 20
+
+// Direction of transaction for address
+type HistoryDirection int
+
+const (
+	Incoming HistoryDirection = iota // address received more than spent
+	Outgoing                         // address spent more than received
+)
+
+// String return name of direction
+func (d HistoryDirection) String() string {
+	switch d {
+	case Incoming:
+		return "incoming"
+	case Outgoing:
+		return "outgoing"
+	}
+	return "unknown"
+}
+
+// Transaction in history of address
+type HistoryEntry struct {
+	Hash           crypto.Hash
+	Direction      HistoryDirection
+	Amount         crypto.Amount // net amount for address, commission is included for outgoing transactions
+	Commission     crypto.Amount // value of commission output
+	Counterparties []string      // senders of incoming or receivers of outgoing transaction
+	Block          crypto.Hash
+	Height         uint32 // height of block, zero for pending transaction
+	Timestamp      uint32 // timestamp of block, zero for pending transaction
+	Confirmations  uint32
+	Tx             *crypto.Tx
+}
+
+// Options of history request. Pending transactions have no block time, so they are returned only without time range
+type HistoryOptions struct {
+	Limit  int       // DefaultHistoryLimit if zero
+	Cursor string    // NextCursor of previous page, empty for the first page, see HistoryPage
+	Since  time.Time // optional, only transactions of blocks with this or later time
+	Until  time.Time // optional, only transactions of blocks before this time
+}
+
+// Page of address history. Cursor is "height:position" of the last entry, where height is height of its block, maximum
+// uint32 for pending transaction, and position is its index in hash list of address. Node keeps the list in order of
+// blocks and adds new transactions to its end, so position doesn't change, when new transactions come. Entries above
+// height of cursor are skipped, so the next page doesn't repeat entries moved down by reorganization
+type HistoryPage struct {
+	Entries    []HistoryEntry // pending transactions first, then confirmed from the newest to the oldest
+	NextCursor string         // cursor of the next page, empty if this page is the last one
+}
+
+// Maximum count of hash lists kept for cursors of history pages
+const historyListsLimit = 100
+
+// GetHistory return decoded transactions of address page by page. Hash list of address is requested for the first page
+// and kept for cursor of the next one, transactions are looked up from the cursor position by parts of page size. Heights
+// of entries are taken from headers of their blocks
+func (cl *Client) GetHistory(address string, opts HistoryOptions) (*HistoryPage, error) {
+	if !crypto.IsWalletAddress(address) {
+		return nil, errors.Errorf("invalid address %s", address)
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = DefaultHistoryLimit
+	}
+	maxHeight := uint32(math.MaxUint32)
+	position := math.MaxInt32
+	var err error
+	if opts.Cursor != "" {
+		if maxHeight, position, err = parseHistoryCursor(opts.Cursor); err != nil {
+			return nil, err
+		}
+	}
+	hashes, ok := cl.history.get(address, opts.Cursor)
+	if !ok {
+		if hashes, err = cl.Tx.GetHashListByAddress(address); err != nil {
+			return nil, err
+		}
+	}
+	if position > len(hashes) {
+		position = len(hashes)
+	}
+
+	// one entry above limit shows that the next page exists
+	entries := make([]HistoryEntry, 0, opts.Limit+1)
+	positions := make([]int, 0, opts.Limit+1)
+	heights := make([]uint32, 0, opts.Limit+1)
+	blockHeights := make(map[crypto.Hash]uint32)
+	for position > 0 && len(entries) <= opts.Limit {
+		from := position - (opts.Limit + 1 - len(entries))
+		if from < 0 {
+			from = 0
+		}
+		txs, err := cl.lookupHistory(hashes[from:position], blockHeights)
+		if err != nil {
+			return nil, err
+		}
+		for i := position - 1; i >= from && len(entries) <= opts.Limit; i-- {
+			txr, ok := txs[hashes[i]]
+			if !ok {
+				continue
+			}
+			height := uint32(math.MaxUint32)
+			if !txr.Block.IsEmpty() {
+				height = blockHeights[txr.Block]
+			}
+			if height > maxHeight {
+				continue
+			}
+			if !opts.Since.IsZero() && !txr.Block.IsEmpty() && blockTime(txr).Before(opts.Since) {
+				// older transactions are before time range too
+				position = 0
+				break
+			}
+			if !inTimeRange(txr, opts.Since, opts.Until) {
+				continue
+			}
+			entry, err := newHistoryEntry(address, txr)
+			if err != nil {
+				return nil, err
+			}
+			if !txr.Block.IsEmpty() {
+				entry.Height = height
+			}
+			entries = append(entries, entry)
+			positions = append(positions, i)
+			heights = append(heights, height)
+		}
+		if from < position {
+			position = from
+		}
+	}
+
+	page := &HistoryPage{Entries: entries}
+	if len(entries) > opts.Limit {
+		last := opts.Limit - 1
+		page.Entries = entries[:opts.Limit]
+		page.NextCursor = fmt.Sprintf("%d:%d", heights[last], positions[last])
+		cl.history.put(address, page.NextCursor, hashes[:positions[last]])
+	}
+	return page, nil
+}
+
+// lookupHistory look up transactions by hashes and add heights of their blocks, which are not known yet. Node returns
+// confirmations instead of heights, they depend on the tip, so blocks of transactions are requested
+func (cl *Client) lookupHistory(hashes []crypto.Hash, heights map[crypto.Hash]uint32) (map[crypto.Hash]*TxResponse, error) {
+	result, err := cl.Tx.LookupHashList(hashes)
+	if err != nil {
+		return nil, err
+	}
+	txs := make(map[crypto.Hash]*TxResponse, len(result.Transactions))
+	missing := make([]crypto.Hash, 0)
+	for i := range result.Transactions {
+		txr := &result.Transactions[i]
+		txs[txr.Hash] = txr
+		if _, ok := heights[txr.Block]; !ok && !txr.Block.IsEmpty() {
+			heights[txr.Block] = 0
+			missing = append(missing, txr.Block)
+		}
+	}
+	if len(missing) == 0 {
+		return txs, nil
+	}
+	blocks, err := cl.Block.getBlocks(missing)
+	if err != nil {
+		return nil, err
+	}
+	for i, block := range blocks {
+		if block.Header.Hash != missing[i] {
+			return nil, errors.Errorf("block %s is returned instead of %s", block.Header.Hash, missing[i])
+		}
+		heights[missing[i]] = block.Header.Height
+	}
+	return txs, nil
+}
+
+// Hash lists of addresses kept for cursors of history pages. Node adds new transactions to the end of the list, so the
+// part of the list before cursor position stays the same
+type historyLists struct {
+	mu    sync.Mutex
+	lists map[string][]crypto.Hash
+	keys  []string // keys in order of adding, the oldest list is dropped over historyListsLimit
+}
+
+// get return kept hash list of address for cursor
+func (hl *historyLists) get(address string, cursor string) ([]crypto.Hash, bool) {
+	hl.mu.Lock()
+	defer hl.mu.Unlock()
+	hashes, ok := hl.lists[address+"/"+cursor]
+	return hashes, ok
+}
+
+// put keep hash list of address for cursor
+func (hl *historyLists) put(address string, cursor string, hashes []crypto.Hash) {
+	hl.mu.Lock()
+	defer hl.mu.Unlock()
+	key := address + "/" + cursor
+	if hl.lists == nil {
+		hl.lists = make(map[string][]crypto.Hash)
+	}
+	if _, ok := hl.lists[key]; !ok {
+		hl.keys = append(hl.keys, key)
+	}
+	hl.lists[key] = hashes
+	if len(hl.keys) > historyListsLimit {
+		delete(hl.lists, hl.keys[0])
+		hl.keys = hl.keys[1:]
+	}
+}
+
+// parseHistoryCursor parse height and position of history cursor
+func parseHistoryCursor(cursor string) (uint32, int, error) {
+	parts := strings.Split(cursor, ":")
+	if len(parts) != 2 {
+		return 0, 0, errors.Errorf("invalid history cursor %s", cursor)
+	}
+	height, err := strconv.ParseUint(parts[0], 10, 32)
+	if err != nil {
+		return 0, 0, errors.Errorf("invalid height of history cursor %s", cursor)
+	}
+	position, err := strconv.ParseUint(parts[1], 10, 31)
+	if err != nil {
+		return 0, 0, errors.Errorf("invalid position of history cursor %s", cursor)
+	}
+	return uint32(height), int(position), nil
+}
+
+// inTimeRange check that transaction is confirmed in time range, zero bounds are not checked
+func inTimeRange(txr *TxResponse, since time.Time, until time.Time) bool {
+	if txr.Block.IsEmpty() {
+		return since.IsZero() && until.IsZero()
+	}
+	timestamp := blockTime(txr)
+	if !since.IsZero() && timestamp.Before(since) {
+		return false
+	}
+	if !until.IsZero() && !timestamp.Before(until) {
+		return false
+	}
+	return true
+}
+
+// blockTime return time of block of confirmed transaction
+func blockTime(txr *TxResponse) time.Time {
+	return time.Unix(int64(txr.ConfirmedTimestamp), 0)
+}
+
+// newHistoryEntry calculate amounts and counterparties of transaction for address
+func newHistoryEntry(address string, txr *TxResponse) (HistoryEntry, error) {
+	script := base58.Decode(address)
+	sent := crypto.Amount(0)
+	senders := make([]string, 0)
+	var err error
+	for _, txIn := range txr.Inputs {
+		if !bytes.Equal(txIn.WalletAddress, script) {
+			senders = appendAddress(senders, txIn.WalletAddress)
+			continue
+		}
+		if sent, err = sent.Add(crypto.Amount(txIn.PreviousOutput.Value)); err != nil {
+			return HistoryEntry{}, err
+		}
+	}
+	received := crypto.Amount(0)
+	commission := crypto.Amount(0)
+	receivers := make([]string, 0)
+	for _, txOut := range txr.Outputs {
+		switch {
+		case len(txOut.WalletAddress) == 0:
+			commission, err = commission.Add(txOut.Amount())
+		case bytes.Equal(txOut.WalletAddress, script):
+			received, err = received.Add(txOut.Amount())
+		default:
+			receivers = appendAddress(receivers, txOut.WalletAddress)
+		}
+		if err != nil {
+			return HistoryEntry{}, err
+		}
+	}
+
+	entry := HistoryEntry{
+		Hash:          txr.Hash,
+		Commission:    commission,
+		Block:         txr.Block,
+		Timestamp:     txr.ConfirmedTimestamp,
+		Confirmations: txr.Confirmed,
+		Tx:            txr.Tx,
+	}
+	if sent > received {
+		entry.Direction = Outgoing
+		entry.Amount = sent - received
+		entry.Counterparties = receivers
+	} else {
+		entry.Direction = Incoming
+		entry.Amount = received - sent
+		entry.Counterparties = senders
+	}
+	return entry, nil
+}
+
+// appendAddress add address to list, if it isn't there yet
+func appendAddress(addresses []string, script []byte) []string {
+	if len(script) == 0 {
+		return addresses
+	}
+	address := base58.Encode(script)
+	for _, existing := range addresses {
+		if existing == address {
+			return addresses
+		}
+	}
+	return append(addresses, address)
+}
@@ -0,0 +1,133 @@
+package httpClient
+
+import (
+	"github.com/velas/GoVelas/crypto"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_GetHistory(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
+	nodeURL, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxy := httputil.NewSingleHostReverseProxy(nodeURL)
+	lists := int32(0)
+	counted := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/api/v1/wallet/txs/") {
+			atomic.AddInt32(&lists, 1)
+		}
+		proxy.ServeHTTP(w, r)
+	}))
+	defer counted.Close()
+	client := NewClient(counted.URL)
+	hd, _ := crypto.HDFromPrivateKeyHex(Pk)
+	wallet, _ := hd.ToWallet()
+	sender := wallet.Base58Address
+	receiver := "VLa1hi77ZXD2BSWDD9wQe8vAhejXyS7vBM4"
+
+	confirmed := sendTestTx(t, client, Pk, receiver, 1000)
+	srv.Mine()
+	pending := sendTestTx(t, client, Pk, receiver, 2000)
+
+	// the first page contains pending and confirmed outgoing transactions
+	first, err := client.GetHistory(sender, HistoryOptions{Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first.Entries) != 2 || first.NextCursor == "" {
+		t.Fatalf("GetHistory() first page got = %+v", first)
+	}
+	if got := first.Entries[0]; got.Hash != pending || got.Direction != Outgoing || got.Amount != 2000+1000000 ||
+		got.Commission != 1000000 || !got.Block.IsEmpty() || got.Confirmations != 0 {
+		t.Errorf("GetHistory() pending entry got = %+v", got)
+	}
+	if got := first.Entries[1]; got.Hash != confirmed || got.Direction != Outgoing || got.Amount != 1000+1000000 ||
+		got.Block.IsEmpty() || got.Confirmations != 1 || got.Timestamp == 0 || int(got.Height) != srv.Height() ||
+		!reflect.DeepEqual(got.Counterparties, []string{receiver}) {
+		t.Errorf("GetHistory() confirmed entry got = %+v", got)
+	}
+
+	// new transactions don't move the cursor
+	sendTestTx(t, client, Pk2, sender, 3000)
+	srv.Mine()
+
+	// the second page contains funding transactions from the newest, hash list is kept for the cursor
+	atomic.StoreInt32(&lists, 0)
+	second, err := client.GetHistory(sender, HistoryOptions{Limit: 2, Cursor: first.NextCursor})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(second.Entries) != 2 || second.NextCursor != "" {
+		t.Fatalf("GetHistory() second page got = %+v", second)
+	}
+	if second.Entries[0].Amount != 200000000 || second.Entries[1].Amount != 100000000 ||
+		second.Entries[0].Direction != Incoming || second.Entries[0].Confirmations >= second.Entries[1].Confirmations {
+		t.Errorf("GetHistory() second page got = %+v", second.Entries)
+	}
+	if got := atomic.LoadInt32(&lists); got != 0 {
+		t.Errorf("GetHistory() of the second page requested %d hash lists, want 0", got)
+	}
+	for _, entry := range second.Entries {
+		header, err := client.Block.GetHeader(entry.Block)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if entry.Height != header.Height {
+			t.Errorf("GetHistory() height got = %d, want %d", entry.Height, header.Height)
+		}
+	}
+
+	incoming, err := client.GetHistory(receiver, HistoryOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(incoming.Entries) != 2 || incoming.Entries[1].Direction != Incoming || incoming.Entries[1].Amount != 1000 ||
+		!reflect.DeepEqual(incoming.Entries[1].Counterparties, []string{sender}) {
+		t.Errorf("GetHistory() of receiver got = %+v", incoming.Entries)
+	}
+
+	unknown, _ := crypto.GenerateHD()
+	unknownWallet, _ := unknown.ToWallet()
+	now := time.Now()
+	tests := []struct {
+		name      string
+		address   string
+		opts      HistoryOptions
+		wantCount int
+		wantErr   bool
+	}{
+		{name: "All", address: sender, opts: HistoryOptions{}, wantCount: 5, wantErr: false},
+		{name: "Since hour ago", address: sender, opts: HistoryOptions{Since: now.Add(-time.Hour)}, wantCount: 5, wantErr: false},
+		{name: "Since hour later", address: sender, opts: HistoryOptions{Since: now.Add(time.Hour)}, wantCount: 0, wantErr: false},
+		{name: "Until hour ago", address: sender, opts: HistoryOptions{Until: now.Add(-time.Hour)}, wantCount: 0, wantErr: false},
+		{name: "Range", address: sender, opts: HistoryOptions{Since: now.Add(-time.Hour), Until: now.Add(time.Hour)}, wantCount: 5, wantErr: false},
+		{name: "Cursor of block", address: sender, opts: HistoryOptions{Cursor: "2:100"}, wantCount: 2, wantErr: false},
+		{name: "Cursor of the oldest", address: sender, opts: HistoryOptions{Cursor: "100:0"}, wantCount: 0, wantErr: false},
+		{name: "Unknown address", address: unknownWallet.Base58Address, opts: HistoryOptions{}, wantCount: 0, wantErr: false},
+		{name: "Invalid address", address: "invalid", opts: HistoryOptions{}, wantErr: true},
+		{name: "Invalid cursor", address: sender, opts: HistoryOptions{Cursor: "00"}, wantErr: true},
+		{name: "Invalid height", address: sender, opts: HistoryOptions{Cursor: "-1:0"}, wantErr: true},
+		{name: "Invalid position", address: sender, opts: HistoryOptions{Cursor: "1:x"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := client.GetHistory(tt.address, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetHistory() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && len(got.Entries) != tt.wantCount {
+				t.Errorf("GetHistory() got %d entries, want %d", len(got.Entries), tt.wantCount)
+			}
+		})
+	}
+}
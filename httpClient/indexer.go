@@ -0,0 +1,409 @@
+package httpClient
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/go-errors/errors"
+	"github.com/velas/GoVelas/crypto"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+)
+
+// Output of address in local index
+type IndexedOutput struct {
+	Outpoint crypto.TransactionInputOutpoint `json:"outpoint"`
+	Height   uint32                          `json:"height"`   // height of block with transaction
+	Stake    bool                            `json:"stake"`    // output has NodeID of validator
+	SpentBy  crypto.Hash                     `json:"spent_by"` // hash of spending transaction, empty if output is unspent
+}
+
+// Transaction of address in local index
+type IndexedTx struct {
+	Hash      crypto.Hash   `json:"hash"`
+	Block     crypto.Hash   `json:"block"`
+	Height    uint32        `json:"height"`
+	Timestamp uint32        `json:"timestamp"` // timestamp of block
+	Received  crypto.Amount `json:"received"`  // sum of outputs to address
+	Sent      crypto.Amount `json:"sent"`      // sum of spent outputs of address
+}
+
+// Index of one address
+type AddressIndex struct {
+	Txs     []IndexedTx      `json:"txs"`     // transactions in order of blocks
+	Outputs []*IndexedOutput `json:"outputs"` // outputs in order of blocks
+}
+
+// State of indexer, which is saved after blocks with transactions of indexed addresses, after rollbacks and at least
+// every DefaultBlockRangeSize blocks. Blocks after saved checkpoint don't change index, so they are just indexed again
+type IndexState struct {
+	Checkpoint *Checkpoint              `json:"checkpoint"` // the last indexed block, nil before the first block
+	Addresses  map[string]*AddressIndex `json:"addresses"`
+}
+
+// Storage for indexer state
+type IndexStore interface {
+	Load() (*IndexState, error) // return nil without error if state is not saved yet
+	Save(state *IndexState) error
+}
+
+// Index store in json file
+type FileIndexStore struct {
+	Path string
+}
+
+// Load state from file, missing file is not an error
+func (fs *FileIndexStore) Load() (*IndexState, error) {
+	data, err := ioutil.ReadFile(fs.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.New(err)
+	}
+	state := IndexState{}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, errors.New(err)
+	}
+	return &state, nil
+}
+
+// Save state to temporary file and rename it, so file always contains full state
+func (fs *FileIndexStore) Save(state *IndexState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return errors.New(err)
+	}
+	return writeFile(fs.Path, data)
+}
+
+// Options of indexer
+type IndexerOptions struct {
+	Addresses     []string      // indexed addresses, required. Saved state must have the same addresses
+	StartHeight   uint32        // height of the first indexed block, if state is not saved yet
+	PollInterval  time.Duration // DefaultFollowerPollInterval if zero
+	MaxReorgDepth int           // DefaultMaxReorgDepth if zero
+	Store         IndexStore    // optional, index is kept only in memory without it
+}
+
+// Indexer walks blocks of node and builds local index of transactions and outputs of configured addresses. History,
+// balance and unspents are answered from the index without requests to node. Blocks of reorganizations are removed
+// from index
+type Indexer struct {
+	cl      *Client
+	opts    IndexerOptions
+	mu      sync.RWMutex
+	state   *IndexState
+	outputs map[outpointKey]ownedOutput // every indexed output
+	unsaved int                         // count of indexed blocks after saved state
+}
+
+// Indexed output with its address
+type ownedOutput struct {
+	address string
+	output  *IndexedOutput
+}
+
+// Create indexer and load saved state from store, call Run for start indexing
+func (cl *Client) NewIndexer(opts IndexerOptions) (*Indexer, error) {
+	if len(opts.Addresses) == 0 {
+		return nil, errors.Errorf("indexed addresses are required")
+	}
+	ix := &Indexer{
+		cl:      cl,
+		opts:    opts,
+		state:   &IndexState{Addresses: make(map[string]*AddressIndex)},
+		outputs: make(map[outpointKey]ownedOutput),
+	}
+	for _, address := range opts.Addresses {
+		if !crypto.IsWalletAddress(address) {
+			return nil, errors.Errorf("invalid address %s", address)
+		}
+		ix.state.Addresses[address] = &AddressIndex{Txs: make([]IndexedTx, 0), Outputs: make([]*IndexedOutput, 0)}
+	}
+	if opts.Store == nil {
+		return ix, nil
+	}
+	state, err := opts.Store.Load()
+	if err != nil {
+		return nil, err
+	}
+	if state == nil {
+		return ix, nil
+	}
+	// history of new address before checkpoint isn't indexed, so index must be built again
+	if len(state.Addresses) != len(ix.state.Addresses) {
+		return nil, errors.Errorf("saved index has %d addresses, want %d", len(state.Addresses), len(ix.state.Addresses))
+	}
+	for address := range ix.state.Addresses {
+		if _, ok := state.Addresses[address]; !ok {
+			return nil, errors.Errorf("saved index doesn't have address %s", address)
+		}
+	}
+	ix.state = state
+	for address, index := range state.Addresses {
+		for _, output := range index.Outputs {
+			ix.outputs[keyOf(output.Outpoint)] = ownedOutput{address: address, output: output}
+		}
+	}
+	return ix, nil
+}
+
+// Run index blocks until context is done or error occurs. State is saved on return, so blocks after the last saved
+// state are not indexed again
+func (ix *Indexer) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	f := ix.cl.NewFollower(FollowerOptions{
+		StartHeight:   ix.opts.StartHeight,
+		PollInterval:  ix.opts.PollInterval,
+		MaxReorgDepth: ix.opts.MaxReorgDepth,
+		Checkpoints:   indexCheckpoints{ix: ix},
+	})
+	errs := make(chan error, 1)
+	go func() { errs <- f.Run(ctx) }()
+
+	for event := range f.Events() {
+		if err := ix.apply(event); err != nil {
+			cancel()
+			for range f.Events() {
+			}
+			<-errs
+			return err
+		}
+	}
+	err := <-errs
+	if saveErr := ix.save(); saveErr != nil {
+		return saveErr
+	}
+	return err
+}
+
+// Checkpoint return the last indexed block, nil if no block is indexed yet
+func (ix *Indexer) Checkpoint() *Checkpoint {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	if ix.state.Checkpoint == nil {
+		return nil
+	}
+	checkpoint := *ix.state.Checkpoint
+	return &checkpoint
+}
+
+// History return indexed transactions of address in order of blocks
+func (ix *Indexer) History(address string) []IndexedTx {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	index, ok := ix.state.Addresses[address]
+	if !ok {
+		return []IndexedTx{}
+	}
+	return append([]IndexedTx{}, index.Txs...)
+}
+
+// Balance return sum of unspent outputs of address, staking outputs are not included like in balance of node
+func (ix *Indexer) Balance(address string) (crypto.Amount, error) {
+	amount := crypto.Amount(0)
+	for _, unspent := range ix.Unspent(address) {
+		var err error
+		if amount, err = amount.Add(crypto.Amount(unspent.Value)); err != nil {
+			return 0, err
+		}
+	}
+	return amount, nil
+}
+
+// Unspent return unspent outputs of address without staking outputs
+func (ix *Indexer) Unspent(address string) []crypto.TransactionInputOutpoint {
+	return ix.unspents(address, false)
+}
+
+// UnspentForStaking return unspent outputs of address including staking outputs
+func (ix *Indexer) UnspentForStaking(address string) []crypto.TransactionInputOutpoint {
+	return ix.unspents(address, true)
+}
+
+// unspents collect unspent outputs of address
+func (ix *Indexer) unspents(address string, withStakes bool) []crypto.TransactionInputOutpoint {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	unspents := make([]crypto.TransactionInputOutpoint, 0)
+	index, ok := ix.state.Addresses[address]
+	if !ok {
+		return unspents
+	}
+	for _, output := range index.Outputs {
+		if !output.SpentBy.IsEmpty() || (output.Stake && !withStakes) {
+			continue
+		}
+		unspents = append(unspents, output.Outpoint)
+	}
+	return unspents
+}
+
+// apply add or remove blocks of follower event and save state, when it is needed
+func (ix *Indexer) apply(event FollowerEvent) error {
+	ix.mu.Lock()
+	ix.unsaved++
+	changed := true
+	switch event.Type {
+	case BlockConnected:
+		if err := checkAmounts(event.Block); err != nil {
+			ix.mu.Unlock()
+			return err
+		}
+		changed = ix.connect(event.Block)
+	case BlockRollback:
+		for _, block := range event.Orphaned {
+			ix.disconnect(block)
+		}
+		if len(event.Orphaned) > 0 {
+			// the last orphaned block is the lowest one, its parent is in main chain
+			lowest := event.Orphaned[len(event.Orphaned)-1].Header
+			ix.state.Checkpoint = &Checkpoint{Height: lowest.Height - 1, Hash: lowest.PrevBlock}
+		}
+	}
+	save := changed || ix.unsaved >= DefaultBlockRangeSize
+	if save {
+		ix.unsaved = 0
+	}
+	ix.mu.Unlock()
+	if !save {
+		return nil
+	}
+	return ix.save()
+}
+
+// checkAmounts check that sums of inputs and outputs of every transaction of block don't overflow, sums of address
+// are parts of them, so connect doesn't check them
+func checkAmounts(block *BlockResponse) error {
+	for _, tx := range block.Transactions {
+		totalIn := crypto.Amount(0)
+		for _, txIn := range tx.Inputs {
+			var err error
+			if totalIn, err = totalIn.Add(crypto.Amount(txIn.PreviousOutput.Value)); err != nil {
+				return err
+			}
+		}
+		totalOut := crypto.Amount(0)
+		for _, txOut := range tx.Outputs {
+			var err error
+			if totalOut, err = totalOut.Add(txOut.Amount()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// connect add transactions of block to index, returns true if index of any address is changed
+func (ix *Indexer) connect(block *BlockResponse) bool {
+	changed := false
+	header := block.Header
+	for _, tx := range block.Transactions {
+		entries := make(map[string]*IndexedTx)
+		entry := func(address string) *IndexedTx {
+			if _, ok := entries[address]; !ok {
+				entries[address] = &IndexedTx{
+					Hash:      tx.Hash,
+					Block:     header.Hash,
+					Height:    header.Height,
+					Timestamp: header.Timestamp,
+				}
+			}
+			return entries[address]
+		}
+
+		for _, txIn := range tx.Inputs {
+			owned, ok := ix.outputs[keyOf(txIn.PreviousOutput)]
+			if !ok {
+				// output of not indexed address or output created before the start block
+				continue
+			}
+			owned.output.SpentBy = tx.Hash
+			entry(owned.address).Sent += crypto.Amount(owned.output.Outpoint.Value)
+		}
+		for _, txOut := range tx.Outputs {
+			address := base58.Encode(txOut.WalletAddress)
+			index, ok := ix.state.Addresses[address]
+			if !ok {
+				// commission output or output of not indexed address
+				continue
+			}
+			output := &IndexedOutput{
+				Outpoint: crypto.TransactionInputOutpoint{Hash: tx.Hash, Index: txOut.Index, Value: txOut.Value},
+				Height:   header.Height,
+				Stake:    !txOut.NodeID.IsEmpty(),
+			}
+			index.Outputs = append(index.Outputs, output)
+			ix.outputs[keyOf(output.Outpoint)] = ownedOutput{address: address, output: output}
+			entry(address).Received += txOut.Amount()
+		}
+
+		for address, indexed := range entries {
+			index := ix.state.Addresses[address]
+			index.Txs = append(index.Txs, *indexed)
+			changed = true
+		}
+	}
+	ix.state.Checkpoint = &Checkpoint{Height: header.Height, Hash: header.Hash}
+	return changed
+}
+
+// disconnect remove transactions of orphaned block from index
+func (ix *Indexer) disconnect(block *BlockResponse) {
+	hashes := make(map[crypto.Hash]bool, len(block.Transactions))
+	for _, tx := range block.Transactions {
+		hashes[tx.Hash] = true
+	}
+	for _, index := range ix.state.Addresses {
+		txs := index.Txs[:0]
+		for _, tx := range index.Txs {
+			if !hashes[tx.Hash] {
+				txs = append(txs, tx)
+			}
+		}
+		index.Txs = txs
+
+		outputs := index.Outputs[:0]
+		for _, output := range index.Outputs {
+			if hashes[output.Outpoint.Hash] {
+				delete(ix.outputs, keyOf(output.Outpoint))
+				continue
+			}
+			if hashes[output.SpentBy] {
+				output.SpentBy = crypto.Hash{}
+			}
+			outputs = append(outputs, output)
+		}
+		index.Outputs = outputs
+	}
+}
+
+// save state to store
+func (ix *Indexer) save() error {
+	if ix.opts.Store == nil {
+		return nil
+	}
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return ix.opts.Store.Save(ix.state)
+}
+
+// Checkpoint store of follower, which is used by indexer. Checkpoint is saved by indexer together with index, after
+// block is processed, so follower saves nothing
+type indexCheckpoints struct {
+	ix *Indexer
+}
+
+// Load return the last indexed block
+func (ic indexCheckpoints) Load() (*Checkpoint, error) {
+	return ic.ix.Checkpoint(), nil
+}
+
+// Save do nothing, see indexCheckpoints
+func (ic indexCheckpoints) Save(checkpoint Checkpoint) error {
+	return nil
+}
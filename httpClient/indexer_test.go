@@ -0,0 +1,139 @@
+package httpClient
+
+import (
+	"bytes"
+	"context"
+	"github.com/velas/GoVelas/crypto"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+// waitIndexed wait until indexer reaches block with hash
+func waitIndexed(t *testing.T, ix *Indexer, hash crypto.Hash) {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if checkpoint := ix.Checkpoint(); checkpoint != nil && checkpoint.Hash == hash {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("timeout of indexing block %s, checkpoint %+v", hash, ix.Checkpoint())
+}
+
+// wantIndexedUnspents compare balance and unspents of indexer with node
+func wantIndexedUnspents(t *testing.T, client *Client, ix *Indexer, address string) {
+	unspents, err := client.Wallet.GetUnspent(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := ix.Unspent(address)
+	// node returns outputs in its own order, compare them as sets
+	for _, outpoints := range [][]crypto.TransactionInputOutpoint{got, unspents} {
+		sort.Slice(outpoints, func(i, j int) bool { return bytes.Compare(outpoints[i].Hash[:], outpoints[j].Hash[:]) < 0 })
+	}
+	if !reflect.DeepEqual(got, unspents) {
+		t.Errorf("Unspent() got = %+v, want %+v", got, unspents)
+	}
+	balance, err := client.Wallet.GetBalance(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := ix.Balance(address); err != nil || got != balance {
+		t.Errorf("Balance() got = %d, error = %v, want %d", got, err, balance)
+	}
+}
+
+func TestIndexer_Run(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
+	dir, err := ioutil.TempDir("", "indexer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store := &FileIndexStore{Path: filepath.Join(dir, "index.json")}
+	client := NewClient(srv.URL)
+	hd, _ := crypto.HDFromPrivateKeyHex(Pk)
+	wallet, _ := hd.ToWallet()
+	sender := wallet.Base58Address
+	receiver := "VLa1hi77ZXD2BSWDD9wQe8vAhejXyS7vBM4"
+	hd2, _ := crypto.HDFromPrivateKeyHex(Pk2)
+	wallet2, _ := hd2.ToWallet()
+	addresses := []string{sender, receiver}
+
+	ix, err := client.NewIndexer(IndexerOptions{Addresses: addresses, PollInterval: 10 * time.Millisecond, Store: store})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() { errs <- ix.Run(ctx) }()
+	waitIndexed(t, ix, srv.TipHash())
+	wantIndexedUnspents(t, client, ix, sender)
+	if got := ix.History(sender); len(got) != 2 || got[0].Received != 100000000 || got[0].Sent != 0 {
+		t.Errorf("History() of funded wallet got = %+v", got)
+	}
+	if got := ix.History(wallet2.Base58Address); len(got) != 0 {
+		t.Errorf("History() of not indexed address got = %+v", got)
+	}
+
+	hash := sendTestTx(t, client, Pk, receiver, 1000)
+	waitIndexed(t, ix, srv.Mine())
+	wantIndexedUnspents(t, client, ix, sender)
+	wantIndexedUnspents(t, client, ix, receiver)
+	history := ix.History(sender)
+	last := history[len(history)-1]
+	if last.Hash != hash || last.Sent != 100000000 || last.Received != 100000000-1000-1000000 {
+		t.Errorf("History() of sender got = %+v", last)
+	}
+	if got := ix.History(receiver); len(got) != 1 || got[0].Hash != hash || got[0].Received != 1000 || got[0].Sent != 0 {
+		t.Errorf("History() of receiver got = %+v", got)
+	}
+
+	// transaction is removed from chain by reorganization
+	if err := srv.Rollback(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.Evict(hash); err != nil {
+		t.Fatal(err)
+	}
+	srv.Mine()
+	waitIndexed(t, ix, srv.Mine())
+	wantIndexedUnspents(t, client, ix, sender)
+	if got := ix.History(receiver); len(got) != 0 || len(ix.Unspent(receiver)) != 0 {
+		t.Errorf("History() of receiver after reorganization got = %+v", got)
+	}
+	if got := ix.History(sender); len(got) != 2 {
+		t.Errorf("History() of sender after reorganization got = %+v", got)
+	}
+
+	cancel()
+	if err := <-errs; err != context.Canceled {
+		t.Errorf("Run() error = %v, want context.Canceled", err)
+	}
+
+	// saved index answers queries without node
+	loaded, err := client.NewIndexer(IndexerOptions{Addresses: addresses, Store: store})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checkpoint := loaded.Checkpoint(); checkpoint == nil || checkpoint.Hash != srv.TipHash() {
+		t.Errorf("Checkpoint() of loaded index got = %+v, want %s", checkpoint, srv.TipHash())
+	}
+	wantIndexedUnspents(t, client, loaded, sender)
+	if !reflect.DeepEqual(loaded.History(sender), ix.History(sender)) {
+		t.Errorf("History() of loaded index got = %+v, want %+v", loaded.History(sender), ix.History(sender))
+	}
+
+	// index of other addresses must be built again
+	for _, other := range [][]string{nil, {sender}, {sender, wallet2.Base58Address}, {sender, "invalid"}} {
+		if _, err := client.NewIndexer(IndexerOptions{Addresses: other, Store: store}); err == nil {
+			t.Errorf("NewIndexer() of addresses %v must fail", other)
+		}
+	}
+}
@@ -1,5 +1,9 @@
 package response
 
+import (
+	"github.com/velas/GoVelas/crypto"
+)
+
 type NodeInfo struct {
 	ID   string `json:"id"`   // Unique node identifier (also the encryption key)
 	Name string `json:"name"` // Name of the node, including client type, version, OS, custom data
@@ -7,9 +11,9 @@ type NodeInfo struct {
 }
 
 type Blockchain struct {
-	Height       int    `json:"height"`
-	CurrentHash  string `json:"current_hash"`
-	CurrentEpoch string `json:"current_epoch"`
+	Height       int         `json:"height"`
+	CurrentHash  crypto.Hash `json:"current_hash"`
+	CurrentEpoch string      `json:"current_epoch"`
 }
 
 // Progress progress of synchronization
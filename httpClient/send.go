@@ -0,0 +1,147 @@
+package httpClient
+
+import (
+	"context"
+	"github.com/go-errors/errors"
+	"github.com/velas/GoVelas/crypto"
+)
+
+// Commission of transactions, created by Send, when options don't set it
+const DefaultCommission = 1000000
+
+// Options of Send
+type SendOptions struct {
+	Commission    crypto.Amount                     // DefaultCommission if zero
+	NodeID        crypto.NodeID                     // node id of change output
+	Payload       []byte                            // optional memo of receiver output, see crypto.TextPayload
+	Unspents      []crypto.TransactionInputOutpoint // outputs for coin selection, unspents of wallet are requested if nil
+	UTXO          *UTXOManager                      // reserve inputs with manager, Unspents are not used then
+	Confirmations uint32                            // wait confirmations of transaction with PublishAndWait, if not zero
+}
+
+// Result of Send
+type SendResult struct {
+	Hash   crypto.Hash                       // hash of published transaction
+	Spent  []crypto.TransactionInputOutpoint // outputs, spent by transaction
+	Tx     *crypto.Tx                        // published transaction
+	Result string                            // result of publish request
+	State  *TxResponse                       // state of transaction after waiting confirmations
+}
+
+// Send amount from wallet of key to address: request unspent outputs, choose inputs, sign transaction, validate it
+// with node and publish. Context is checked between requests and used for waiting confirmations
+func (cl *Client) Send(ctx context.Context, key crypto.HD, to string, amount crypto.Amount, opts SendOptions) (*SendResult, error) {
+	if !crypto.IsWalletAddress(to) {
+		return nil, errors.Errorf("invalid address %s", to)
+	}
+	if amount == 0 {
+		return nil, errors.Errorf("amount must be positive")
+	}
+	commission := opts.Commission
+	if commission == 0 {
+		commission = DefaultCommission
+	}
+	target, err := amount.Add(commission)
+	if err != nil {
+		return nil, err
+	}
+	wallet, err := key.ToWallet()
+	if err != nil {
+		return nil, err
+	}
+
+	var selected []crypto.TransactionInputOutpoint
+	var reservation *Reservation
+	if opts.UTXO != nil {
+		reservation, err = opts.UTXO.Reserve(wallet.Base58Address, target)
+		if err != nil {
+			return nil, err
+		}
+		selected = reservation.Outpoints
+	} else {
+		unspents := opts.Unspents
+		if unspents == nil {
+			unspents, err = cl.Wallet.GetUnspent(wallet.Base58Address)
+			if err != nil {
+				return nil, err
+			}
+		}
+		selected, err = crypto.SelectUnspents(unspents, target)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := cl.sendSelected(ctx, key, wallet.Base58Address, selected, to, amount, commission, opts)
+	if reservation != nil {
+		reservation.finishPublish(result, err)
+	}
+	return result, err
+}
+
+// sendSelected build transaction from selected outputs, validate and publish it. Result is not nil, if transaction
+// is published
+func (cl *Client) sendSelected(
+	ctx context.Context,
+	key crypto.HD,
+	from string,
+	selected []crypto.TransactionInputOutpoint,
+	to string,
+	amount crypto.Amount,
+	commission crypto.Amount,
+	opts SendOptions,
+) (*SendResult, error) {
+	receivers := []crypto.Receiver{{Wallet: to, Amount: amount, Payload: opts.Payload}}
+	tx, err := crypto.NewTransactionWithOptions(selected, key, from, receivers, commission,
+		crypto.TxOptions{ChangeNodeID: opts.NodeID})
+	if err != nil {
+		return nil, err
+	}
+	return cl.publishTx(ctx, tx, selected, opts.Confirmations)
+}
+
+// publishTx validate transaction with node and publish it, wait confirmations if they are not zero. Result is not nil,
+// if transaction is published
+func (cl *Client) publishTx(
+	ctx context.Context,
+	tx *crypto.Tx,
+	spent []crypto.TransactionInputOutpoint,
+	confirmations uint32,
+) (*SendResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := cl.Tx.Validate(*tx); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	result := &SendResult{
+		Hash:  tx.Hash,
+		Spent: spent,
+		Tx:    tx,
+	}
+	if confirmations == 0 {
+		response, err := cl.Tx.publish(*tx)
+		if err != nil {
+			return nil, err
+		}
+		result.Result = response.Result
+		return result, nil
+	}
+	published, err := cl.Tx.PublishAndWait(ctx, *tx, confirmations)
+	if published != nil {
+		result.Result = published.Result
+		result.State = published.Tx
+	}
+	if err != nil {
+		if published != nil {
+			// transaction is published, caller needs its hash for further checks
+			return result, err
+		}
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,190 @@
+package httpClient
+
+import (
+	"bytes"
+	"context"
+	"github.com/velas/GoVelas/crypto"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestClient_Send(t *testing.T) {
+	const to = "VLa1hi77ZXD2BSWDD9wQe8vAhejXyS7vBM4"
+	type args struct {
+		privateKey string
+		to         string
+		amount     crypto.Amount
+		opts       SendOptions
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantSpent  []uint64
+		wantChange uint64
+		wantErr    bool
+	}{
+		{
+			name:       "Single input",
+			args:       args{privateKey: Pk, to: to, amount: 50000000},
+			wantSpent:  []uint64{100000000},
+			wantChange: 100000000 - 50000000 - DefaultCommission,
+			wantErr:    false,
+		},
+		{
+			name:       "Two inputs",
+			args:       args{privateKey: Pk, to: to, amount: 250000000, opts: SendOptions{Commission: 2000000}},
+			wantSpent:  []uint64{100000000, 200000000},
+			wantChange: 300000000 - 250000000 - 2000000,
+			wantErr:    false,
+		},
+		{
+			name:       "With payload",
+			args:       args{privateKey: Pk, to: to, amount: 50000000, opts: SendOptions{Payload: []byte("invoice 42")}},
+			wantSpent:  []uint64{100000000},
+			wantChange: 100000000 - 50000000 - DefaultCommission,
+			wantErr:    false,
+		},
+		{
+			name:    "Insufficient funds",
+			args:    args{privateKey: Pk, to: to, amount: 300000000},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid address",
+			args:    args{privateKey: Pk, to: "VLinvalid", amount: 1000},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newTestServer(t)
+			defer srv.Close()
+			client := NewClient(srv.URL)
+			hd, _ := crypto.HDFromPrivateKeyHex(tt.args.privateKey)
+			got, err := client.Send(context.Background(), *hd, tt.args.to, tt.args.amount, tt.args.opts)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Send() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			gotSpent := make([]uint64, 0)
+			for _, spent := range got.Spent {
+				gotSpent = append(gotSpent, spent.Value)
+			}
+			if !reflect.DeepEqual(gotSpent, tt.wantSpent) {
+				t.Errorf("Send() spent = %v, want %v", gotSpent, tt.wantSpent)
+			}
+			if got.Result != got.Hash.String() {
+				t.Errorf("Send() result = %s, want %s", got.Result, got.Hash)
+			}
+			if !bytes.Equal(got.Tx.Outputs[1].Payload, tt.args.opts.Payload) {
+				t.Errorf("Send() payload = %q, want %q", got.Tx.Outputs[1].Payload, tt.args.opts.Payload)
+			}
+			srv.Mine()
+			wallet, _ := hd.ToWallet()
+			unspents, _ := client.Wallet.GetUnspent(wallet.Base58Address)
+			gotChange := uint64(0)
+			for _, unspent := range unspents {
+				if unspent.Hash == got.Tx.Hash {
+					gotChange = unspent.Value
+				}
+			}
+			if gotChange != tt.wantChange {
+				t.Errorf("Send() change = %d, want %d", gotChange, tt.wantChange)
+			}
+		})
+	}
+}
+
+func TestClient_SendAndWait(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
+	srv.SetAutoMine(true)
+	client := NewClient(srv.URL)
+	client.Tx.WaitPollInterval = 10 * time.Millisecond
+	hd, _ := crypto.HDFromPrivateKeyHex(Pk)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	got, err := client.Send(ctx, *hd, "VLa1hi77ZXD2BSWDD9wQe8vAhejXyS7vBM4", 1000, SendOptions{Confirmations: 1})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if got.State == nil || got.State.Block.IsEmpty() || got.State.Confirmed != 1 {
+		t.Errorf("Send() state = %+v, want confirmed transaction", got.State)
+	}
+}
+
+func TestClient_SendUnconfirmed(t *testing.T) {
+	const to = "VLa1hi77ZXD2BSWDD9wQe8vAhejXyS7vBM4"
+	tests := []struct {
+		name          string
+		timeout       time.Duration
+		evict         bool
+		wantErr       error
+		wantAvailable bool // outputs of transaction are available right after Send
+	}{
+		{name: "Evicted", timeout: 5 * time.Second, evict: true, wantErr: ErrTxEvicted, wantAvailable: true},
+		{name: "Timeout", timeout: 50 * time.Millisecond, evict: false, wantErr: ErrTxTimeout, wantAvailable: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newTestServer(t)
+			defer srv.Close()
+			client := NewClient(srv.URL)
+			client.Tx.WaitPollInterval = 10 * time.Millisecond
+			client.Tx.WaitGracePolls = 0
+			hd, _ := crypto.HDFromPrivateKeyHex(Pk)
+			wallet, _ := hd.ToWallet()
+			m := client.NewUTXOManager(UTXOManagerOptions{CacheTTL: time.Hour, ReservationTTL: time.Second})
+			now := time.Now()
+			m.now = func() time.Time { return now }
+			before, err := m.Available(wallet.Base58Address)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			done := make(chan struct{})
+			defer close(done)
+			if tt.evict {
+				// node evicts every transaction of wallet after it is published
+				go func() {
+					for {
+						if pending, _ := client.Tx.GetHashListByAddress(to); len(pending) > 0 {
+							for _, hash := range pending {
+								_ = srv.Evict(hash)
+							}
+						}
+						select {
+						case <-done:
+							return
+						case <-time.After(time.Millisecond):
+						}
+					}
+				}()
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), tt.timeout)
+			defer cancel()
+			got, err := client.Send(ctx, *hd, to, 1000, SendOptions{UTXO: m, Confirmations: 1})
+			if err != tt.wantErr {
+				t.Fatalf("Send() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			available, err := m.Available(wallet.Base58Address)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if gotAvailable := len(available) == len(before); gotAvailable != tt.wantAvailable {
+				t.Errorf("Available() after Send() got = %v, want all %v: %v", available, tt.wantAvailable, got.Spent)
+			}
+
+			// kept reservation expires
+			now = now.Add(2 * time.Second)
+			if available, _ := m.Available(wallet.Base58Address); len(available) != len(before) {
+				t.Errorf("Available() after expiration got = %v, want %v", available, before)
+			}
+		})
+	}
+}
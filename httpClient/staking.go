@@ -0,0 +1,32 @@
+package httpClient
+
+import (
+	"github.com/velas/GoVelas/crypto"
+	"github.com/velas/GoVelas/crypto/staking"
+)
+
+// GetStakes return active stakes of address, transactions of unspent_for_staking outputs are requested for NodeID
+func (cl *Client) GetStakes(address string) ([]staking.Stake, error) {
+	unspents, err := cl.Wallet.GetUnspentForStaking(address)
+	if err != nil {
+		return nil, err
+	}
+	if len(unspents) == 0 {
+		return []staking.Stake{}, nil
+	}
+	hashes := make([]crypto.Hash, 0, len(unspents))
+	for _, unspent := range unspents {
+		hashes = append(hashes, unspent.Hash)
+	}
+	result, err := cl.Tx.LookupHashList(hashes)
+	if err != nil {
+		return nil, err
+	}
+	txs := make([]crypto.Tx, 0, len(result.Transactions))
+	for _, txResponse := range result.Transactions {
+		if txResponse.Tx != nil {
+			txs = append(txs, *txResponse.Tx)
+		}
+	}
+	return staking.FindStakes(address, unspents, txs), nil
+}
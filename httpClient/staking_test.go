@@ -0,0 +1,60 @@
+package httpClient
+
+import (
+	"github.com/velas/GoVelas/crypto"
+	"github.com/velas/GoVelas/crypto/staking"
+	"testing"
+)
+
+func TestClient_GetStakes(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
+	client := NewClient(srv.URL)
+	hd, _ := crypto.HDFromPrivateKeyHex(Pk2)
+	wallet, _ := hd.ToWallet()
+	validator := crypto.NodeID{1, 2, 3}
+
+	unspents, _ := client.Wallet.GetUnspent(wallet.Base58Address)
+	tx, err := staking.NewStake(unspents, *hd, validator, 1000000000, DefaultCommission)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Tx.Publish(*tx); err != nil {
+		t.Fatal(err)
+	}
+	srv.Mine()
+
+	stakes, err := client.GetStakes(wallet.Base58Address)
+	if err != nil {
+		t.Fatalf("GetStakes() error = %v", err)
+	}
+	if len(stakes) != 1 || stakes[0].Validator != validator || stakes[0].Outpoint.Value != 1000000000 {
+		t.Fatalf("GetStakes() got %+v", stakes)
+	}
+
+	split, err := staking.Split(stakes[0], *hd, []crypto.Amount{400000000}, DefaultCommission)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Tx.Publish(*split); err != nil {
+		t.Fatal(err)
+	}
+	srv.Mine()
+	stakes, _ = client.GetStakes(wallet.Base58Address)
+	if len(stakes) != 2 {
+		t.Fatalf("GetStakes() after split got %d stakes, want 2", len(stakes))
+	}
+
+	withdraw, err := staking.Withdraw(stakes, *hd, DefaultCommission)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Tx.Publish(*withdraw); err != nil {
+		t.Fatal(err)
+	}
+	srv.Mine()
+	stakes, _ = client.GetStakes(wallet.Base58Address)
+	if len(stakes) != 0 {
+		t.Errorf("GetStakes() after withdraw got %d stakes, want 0", len(stakes))
+	}
+}
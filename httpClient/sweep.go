@@ -0,0 +1,76 @@
+package httpClient
+
+import (
+	"context"
+	"github.com/go-errors/errors"
+	"github.com/velas/GoVelas/crypto"
+)
+
+// Options of Sweep
+type SweepOptions struct {
+	FeeRates      *crypto.FeeRates // rates for commission, crypto.DefaultFeeRates if nil
+	UTXO          *UTXOManager     // skip outputs reserved by manager and mark swept outputs as spent
+	Confirmations uint32           // wait confirmations of transaction with PublishAndWait, if not zero
+}
+
+// Sweep send all unspent outputs of keys to one address with one transaction without change, commission is deducted
+// from the sent amount. It is used for key rotation and migration of accounts
+func (cl *Client) Sweep(ctx context.Context, keys []crypto.HD, to string, opts SweepOptions) (*SendResult, error) {
+	if !crypto.IsWalletAddress(to) {
+		return nil, errors.Errorf("invalid address %s", to)
+	}
+	if len(keys) == 0 {
+		return nil, errors.Errorf("no keys for sweep")
+	}
+	rates := crypto.DefaultFeeRates
+	if opts.FeeRates != nil {
+		rates = *opts.FeeRates
+	}
+
+	sources := make([]crypto.SweepSource, 0, len(keys))
+	reservations := make([]*Reservation, 0)
+	release := func() {
+		for _, reservation := range reservations {
+			reservation.Release()
+		}
+	}
+	spent := make([]crypto.TransactionInputOutpoint, 0)
+	for _, key := range keys {
+		wallet, err := key.ToWallet()
+		if err != nil {
+			release()
+			return nil, err
+		}
+		var unspents []crypto.TransactionInputOutpoint
+		if opts.UTXO != nil {
+			reservation, err := opts.UTXO.ReserveAll(wallet.Base58Address)
+			if err != nil {
+				release()
+				return nil, err
+			}
+			reservations = append(reservations, reservation)
+			unspents = reservation.Outpoints
+		} else {
+			unspents, err = cl.Wallet.GetUnspent(wallet.Base58Address)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if len(unspents) == 0 {
+			continue
+		}
+		sources = append(sources, crypto.SweepSource{Key: key, Unspents: unspents})
+		spent = append(spent, unspents...)
+	}
+
+	tx, err := crypto.NewSweepTransaction(sources, to, rates)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	result, err := cl.publishTx(ctx, tx, spent, opts.Confirmations)
+	for _, reservation := range reservations {
+		reservation.finishPublish(result, err)
+	}
+	return result, err
+}
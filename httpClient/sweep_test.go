@@ -0,0 +1,102 @@
+package httpClient
+
+import (
+	"context"
+	"github.com/velas/GoVelas/crypto"
+	"testing"
+	"time"
+)
+
+func TestClient_Sweep(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
+	client := NewClient(srv.URL)
+
+	first, _ := crypto.HDFromPrivateKeyHex(Pk)
+	firstWallet, _ := first.ToWallet()
+	second, _ := crypto.GenerateHD()
+	secondWallet, _ := second.ToWallet()
+	if _, err := srv.Fund(secondWallet.Base58Address, 50000000); err != nil {
+		t.Fatal(err)
+	}
+	target, _ := crypto.GenerateHD()
+	targetWallet, _ := target.ToWallet()
+	rates := crypto.FeeRates{Base: 100000, PerByte: 1000, Min: 1000000}
+	srv.SetFeeRates(rates)
+
+	utxo := client.NewUTXOManager(UTXOManagerOptions{})
+	got, err := client.Sweep(context.Background(), []crypto.HD{*first, *second}, targetWallet.Base58Address,
+		SweepOptions{FeeRates: &rates, UTXO: utxo})
+	if err != nil {
+		t.Fatalf("Sweep() error = %v", err)
+	}
+	if len(got.Spent) != 3 || len(got.Tx.Outputs) != 2 {
+		t.Fatalf("Sweep() spent %d outputs and created %d outputs, want 3 and 2", len(got.Spent), len(got.Tx.Outputs))
+	}
+	commission, _ := rates.ForTx(got.Tx)
+	if got.Tx.Outputs[0].Amount() != commission {
+		t.Errorf("Sweep() commission = %d, want %d", got.Tx.Outputs[0].Value, commission)
+	}
+	if available, _ := utxo.Available(firstWallet.Base58Address); len(available) != 0 {
+		t.Errorf("swept outputs must not be available, got %d", len(available))
+	}
+
+	srv.Mine()
+	for _, address := range []string{firstWallet.Base58Address, secondWallet.Base58Address} {
+		if balance, _ := client.Wallet.GetBalance(address); balance != 0 {
+			t.Errorf("balance of %s = %d, want 0", address, balance)
+		}
+	}
+	balance, _ := client.Wallet.GetBalance(targetWallet.Base58Address)
+	if want := crypto.Amount(350000000 - commission); balance != want {
+		t.Errorf("balance of target = %d, want %d", balance, want)
+	}
+
+	if _, err := client.Sweep(context.Background(), []crypto.HD{*first}, targetWallet.Base58Address, SweepOptions{}); err == nil {
+		t.Errorf("Sweep() of empty wallet must fail")
+	}
+}
+
+func TestClient_SweepEvicted(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
+	client := NewClient(srv.URL)
+	client.Tx.WaitPollInterval = 10 * time.Millisecond
+	client.Tx.WaitGracePolls = 0
+	key, _ := crypto.HDFromPrivateKeyHex(Pk)
+	wallet, _ := key.ToWallet()
+	target, _ := crypto.GenerateHD()
+	targetWallet, _ := target.ToWallet()
+	utxo := client.NewUTXOManager(UTXOManagerOptions{})
+	before, err := utxo.Available(wallet.Base58Address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// node evicts the sweep transaction after it is published
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			if pending, _ := client.Tx.GetHashListByAddress(targetWallet.Base58Address); len(pending) > 0 {
+				_ = srv.Evict(pending[0])
+				return
+			}
+			select {
+			case <-done:
+				return
+			case <-time.After(time.Millisecond):
+			}
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = client.Sweep(ctx, []crypto.HD{*key}, targetWallet.Base58Address, SweepOptions{UTXO: utxo, Confirmations: 1})
+	if err != ErrTxEvicted {
+		t.Fatalf("Sweep() error = %v, want %v", err, ErrTxEvicted)
+	}
+	if available, _ := utxo.Available(wallet.Base58Address); len(available) != len(before) {
+		t.Errorf("Available() after eviction got = %v, want %v", available, before)
+	}
+}
@@ -1,66 +1,93 @@
 package httpClient
 
 import (
-	"encoding/hex"
+	"context"
 	"encoding/json"
 	"github.com/go-errors/errors"
 	"github.com/velas/GoVelas/crypto"
 	"gopkg.in/resty.v1"
 	"strconv"
+	"sync"
+	"time"
 )
 
 // Transaction response from node
 type TxResponse struct {
-	Size               uint32 `json:"size"`
-	Block              string `json:"block"` // block hash
-	Confirmed          uint32 `json:"confirmed"`
-	ConfirmedTimestamp uint32 `json:"confirmed_timestamp"`
-	Total              int    `json:"total,omitempty"`
+	Size               uint32      `json:"size"`
+	Block              crypto.Hash `json:"block"` // block hash, empty while transaction is not in a block
+	Confirmed          uint32      `json:"confirmed"`
+	ConfirmedTimestamp uint32      `json:"confirmed_timestamp"`
+	Total              int         `json:"total,omitempty"`
 	*crypto.Tx
 }
 
-// Custom unmarshaller of transaction response
+// Custom unmarshaller of transaction response. Transaction is decoded separately, because embedded crypto.Tx has its
+// own unmarshaller, which hides the response fields
 func (txr *TxResponse) UnmarshalJSON(data []byte) error {
-	type Alias TxResponse
-	aux := &struct {
-		Hash string `json:"hash"`
-		*Alias
-	}{
-		Alias: (*Alias)(txr),
-	}
-	if aux.Tx == nil {
-		aux.Alias.Tx = &crypto.Tx{}
-	}
-	if err := json.Unmarshal(data, &aux); err != nil {
+	tx := &crypto.Tx{}
+	if err := json.Unmarshal(data, tx); err != nil {
 		return err
 	}
-	pHash, err := hex.DecodeString(aux.Hash)
-	if err != nil {
+	aux := struct {
+		Size               uint32      `json:"size"`
+		Block              crypto.Hash `json:"block"`
+		Confirmed          uint32      `json:"confirmed"`
+		ConfirmedTimestamp uint32      `json:"confirmed_timestamp"`
+		Total              int         `json:"total,omitempty"`
+	}{}
+	if err := json.Unmarshal(data, &aux); err != nil {
 		return err
 	}
-
-	var hash [32]byte
-	if len(pHash) == 32 {
-		copy(hash[:], pHash[:32])
-	}
-	txr.Hash = hash
+	txr.Size = aux.Size
+	txr.Block = aux.Block
+	txr.Confirmed = aux.Confirmed
+	txr.ConfirmedTimestamp = aux.ConfirmedTimestamp
+	txr.Total = aux.Total
+	txr.Tx = tx
 	return nil
 }
 
+// Maximum count of hashes in one request of GetByHashList(can change later)
+const DefaultHashListChunkSize = 10000
+
+// Count of concurrent requests in GetByHashList
+const DefaultHashListWorkers = 4
+
+// Interval between checks of published transaction in PublishAndWait
+const DefaultWaitPollInterval = 5 * time.Second
+
+// Count of checks in a row in PublishAndWait, which may miss published transaction. Node can return just published
+// transaction with a delay and a lagging node or a node behind a load balancer can miss already seen transaction
+const DefaultWaitGracePolls = 3
+
+// Error of PublishAndWait, when context deadline is exceeded before transaction got confirmations
+var ErrTxTimeout = errors.New("transaction is not confirmed before timeout")
+
+// Error of PublishAndWait, when node doesn't know published transaction anymore
+var ErrTxEvicted = errors.New("transaction is evicted by node")
+
 // Transaction client
 type Tx struct {
-	bk *baseClient
+	bk               *baseClient
+	HashListChunk    int           // maximum hashes in one request, lists are split to chunks of this size
+	HashListWorkers  int           // maximum concurrent requests for one hash list
+	WaitPollInterval time.Duration // interval between checks of transaction in PublishAndWait
+	WaitGracePolls   int           // checks in a row in PublishAndWait, which may miss transaction
 }
 
 // create transaction client
 func newTxClient(bk *baseClient) *Tx {
 	return &Tx{
-		bk: bk,
+		bk:               bk,
+		HashListChunk:    DefaultHashListChunkSize,
+		HashListWorkers:  DefaultHashListWorkers,
+		WaitPollInterval: DefaultWaitPollInterval,
+		WaitGracePolls:   DefaultWaitGracePolls,
 	}
 }
 
 // Get an array of transaction hashes by wallet address
-func (tx *Tx) GetHashListByAddress(address string) ([]string, error) {
+func (tx *Tx) GetHashListByAddress(address string) ([]crypto.Hash, error) {
 	resp, err := resty.
 		R().
 		Get(tx.bk.baseAddress + "/api/v1/wallet/txs/" + address)
@@ -68,7 +95,7 @@ func (tx *Tx) GetHashListByAddress(address string) ([]string, error) {
 		return nil, errors.New(err)
 	}
 	body, err := tx.bk.ReadResponse(resp)
-	response := make([]string, 0)
+	response := make([]crypto.Hash, 0)
 	if err := json.Unmarshal(body, &response); err != nil {
 		return nil, errors.New(err)
 	}
@@ -76,7 +103,7 @@ func (tx *Tx) GetHashListByAddress(address string) ([]string, error) {
 }
 
 // Get an array of transaction hashes by range blocks from the given height to the highest block
-func (tx *Tx) GetHashListByHeight(height int) ([]string, error) {
+func (tx *Tx) GetHashListByHeight(height int) ([]crypto.Hash, error) {
 	resp, err := resty.
 		R().
 		Get(tx.bk.baseAddress + "/api/v1/txs/height/" + strconv.Itoa(height))
@@ -87,17 +114,113 @@ func (tx *Tx) GetHashListByHeight(height int) ([]string, error) {
 	if err != nil {
 		return nil, err
 	}
-	response := make([]string, 0)
+	response := make([]crypto.Hash, 0)
 	if err := json.Unmarshal(body, &response); err != nil {
 		return nil, errors.New(err)
 	}
 	return response, nil
 }
 
-// Get array of transaction objects by hash list, maximum hashes is 10000(can change later)
-func (tx *Tx) GetByHashList(hashes []string) ([]TxResponse, error) {
+// Result of hash list lookup
+type HashListResult struct {
+	Transactions []TxResponse  // found transactions in order of requested hashes
+	Missing      []crypto.Hash // requested hashes, which node doesn't return
+}
+
+// Get array of transaction objects by hash list in order of hashes, unknown hashes are skipped. Long lists are split to
+// chunks of HashListChunk size and requested concurrently
+func (tx *Tx) GetByHashList(hashes []crypto.Hash) ([]TxResponse, error) {
+	result, err := tx.LookupHashList(hashes)
+	if err != nil {
+		return nil, err
+	}
+	return result.Transactions, nil
+}
+
+// LookupHashList get transaction objects by hash list like GetByHashList and report hashes, which are missing in the
+// node response. Duplicated hashes are requested once
+func (tx *Tx) LookupHashList(hashes []crypto.Hash) (*HashListResult, error) {
+	unique := make([]crypto.Hash, 0, len(hashes))
+	seen := make(map[crypto.Hash]bool)
+	for _, hash := range hashes {
+		if seen[hash] {
+			continue
+		}
+		seen[hash] = true
+		unique = append(unique, hash)
+	}
+
+	chunkSize := tx.HashListChunk
+	if chunkSize <= 0 {
+		chunkSize = DefaultHashListChunkSize
+	}
+	chunks := make([][]crypto.Hash, 0, len(unique)/chunkSize+1)
+	for start := 0; start < len(unique); start += chunkSize {
+		end := start + chunkSize
+		if end > len(unique) {
+			end = len(unique)
+		}
+		chunks = append(chunks, unique[start:end])
+	}
+
+	workers := tx.HashListWorkers
+	if workers <= 0 {
+		workers = 1
+	}
+	if workers > len(chunks) {
+		workers = len(chunks)
+	}
+
+	responses := make([][]TxResponse, len(chunks))
+	errs := make([]error, len(chunks))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				responses[job], errs[job] = tx.getByHashListChunk(chunks[job])
+			}
+		}()
+	}
+	for job := range chunks {
+		jobs <- job
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	found := make(map[crypto.Hash]TxResponse)
+	for _, chunkResponse := range responses {
+		for _, txResponse := range chunkResponse {
+			found[txResponse.Hash] = txResponse
+		}
+	}
+	result := &HashListResult{
+		Transactions: make([]TxResponse, 0, len(unique)),
+		Missing:      make([]crypto.Hash, 0),
+	}
+	for _, hash := range unique {
+		txResponse, ok := found[hash]
+		if !ok {
+			result.Missing = append(result.Missing, hash)
+			continue
+		}
+		result.Transactions = append(result.Transactions, txResponse)
+	}
+	return result, nil
+}
+
+// Get array of transaction objects by hash list in one request
+func (tx *Tx) getByHashListChunk(hashes []crypto.Hash) ([]TxResponse, error) {
 	arg := struct {
-		Hashes []string `json:"hashes"`
+		Hashes []crypto.Hash `json:"hashes"`
 	}{Hashes: hashes}
 	resp, err := resty.
 		R().
@@ -145,20 +268,75 @@ func (tx *Tx) Validate(txData crypto.Tx) error {
 
 // Publish transaction in blockchain
 func (tx *Tx) Publish(txData crypto.Tx) error {
+	_, err := tx.publish(txData)
+	return err
+}
+
+// Result of PublishAndWait
+type PublishResult struct {
+	Result string      // result of publish request
+	Tx     *TxResponse // last state of transaction, nil if it was not found
+}
+
+// PublishAndWait publish transaction and wait, while it is included in a block and has required count of
+// confirmations. Transaction is checked every WaitPollInterval. If context deadline is exceeded ErrTxTimeout is
+// returned. ErrTxEvicted is returned if node doesn't return transaction in more than WaitGracePolls checks in a row,
+// before or after it was seen. Result contains last known state in both cases
+func (tx *Tx) PublishAndWait(ctx context.Context, txData crypto.Tx, confirmations uint32) (*PublishResult, error) {
+	response, err := tx.publish(txData)
+	if err != nil {
+		return nil, err
+	}
+	result := &PublishResult{Result: response.Result}
+	interval := tx.WaitPollInterval
+	if interval <= 0 {
+		interval = DefaultWaitPollInterval
+	}
+	for misses := 0; ; {
+		found, err := tx.LookupHashList([]crypto.Hash{txData.Hash})
+		if err != nil {
+			return result, err
+		}
+		if len(found.Transactions) > 0 {
+			misses = 0
+			result.Tx = &found.Transactions[0]
+			if !result.Tx.Block.IsEmpty() && result.Tx.Confirmed >= confirmations {
+				return result, nil
+			}
+		} else {
+			misses++
+			if misses > tx.WaitGracePolls {
+				return result, ErrTxEvicted
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				return result, ErrTxTimeout
+			}
+			return result, ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// publish transaction and return response of node
+func (tx *Tx) publish(txData crypto.Tx) (*TxPublishResponse, error) {
 	resp, err := resty.
 		R().
 		SetBody(&txData).
 		Post(tx.bk.baseAddress + "/api/v1/txs/publish")
 	if err != nil {
-		return errors.New(err)
+		return nil, errors.New(err)
 	}
 	body, err := tx.bk.ReadResponse(resp)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	response := TxPublishResponse{}
 	if err := json.Unmarshal(body, &response); err != nil {
-		return errors.New(err)
+		return nil, errors.New(err)
 	}
-	return nil
+	return &response, nil
 }
@@ -1,14 +1,24 @@
 package httpClient
 
 import (
+	"context"
 	"encoding/hex"
 	"github.com/velas/GoVelas/crypto"
 	"github.com/velas/GoVelas/crypto/helpers"
+	"github.com/velas/GoVelas/httpClient/velastest"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
 	"reflect"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestTx_GetListByAddress(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
 	type fields struct {
 		baseAddress string
 	}
@@ -19,12 +29,12 @@ func TestTx_GetListByAddress(t *testing.T) {
 		name    string
 		fields  fields
 		args    args
-		want    []string
+		want    []crypto.Hash
 		wantErr bool
 	}{
 		{
 			name:    "Normal test",
-			fields:  fields{baseAddress: Url},
+			fields:  fields{baseAddress: srv.URL},
 			args:    args{privateKey: Pk},
 			wantErr: false,
 		},
@@ -47,52 +57,47 @@ func TestTx_GetListByAddress(t *testing.T) {
 	}
 }
 
-// Test doesn't work correct
 func TestTx_GetByHashList(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
+	hd, _ := crypto.HDFromPrivateKeyHex(Pk)
+	wallet, _ := hd.ToWallet()
+	funded, err := NewClient(srv.URL).Tx.GetHashListByAddress(wallet.Base58Address)
+	if err != nil {
+		t.Fatal(err)
+	}
 	type fields struct {
 		baseAddress string
 	}
 	type args struct {
-		hashes []string
+		hashes []crypto.Hash
+	}
+	type want struct {
+		hash      crypto.Hash
+		confirmed uint32
 	}
 	tests := []struct {
 		name    string
 		fields  fields
 		args    args
-		want    []TxResponse
+		want    []want
 		wantErr bool
 	}{
 		{
 			name:   "Normal test",
-			fields: fields{baseAddress: Url},
-			args: args{hashes: []string{
-				"de7efc5dff6860bdb78758a4851c48ab284c039b85320a7dd334648cb787e317",
-				"ca4161d7743a93d4a1c5c4ba8462435dc1a23219941fea5cebc26ff93d2bcec6",
-			}},
-			want: []TxResponse{
-				{
-					Size:               39,
-					Block:              "519cb2a564e0e682540663af09ef56f9068aeb5c51f8f852a3011ff860a479f9",
-					Confirmed:          7745,
-					ConfirmedTimestamp: 1565085672,
-					Total:              0,
-					Tx:                 nil,
-				}, {
-					Size:               38,
-					Block:              "498ae932b8066aefa14596547111fa957e9a42dd39cbac993564df2e1102f280",
-					Confirmed:          85229,
-					ConfirmedTimestamp: 1563535015,
-					Total:              0,
-					Tx:                 nil,
-				},
+			fields: fields{baseAddress: srv.URL},
+			args:   args{hashes: funded},
+			want: []want{
+				{hash: funded[0], confirmed: 3},
+				{hash: funded[1], confirmed: 2},
 			},
 			wantErr: false,
 		},
 		{
-			name:    "check tx confirmed",
-			args:    args{hashes: []string{"53ab5f62deac40f68e18c0600e775c95ccde6b6fa7e9bc552d6109431571896f"}},
-			fields:  fields{baseAddress: Url},
-			want:    nil,
+			name:    "unknown hash",
+			args:    args{hashes: []crypto.Hash{crypto.DHASH([]byte("unknown"))}},
+			fields:  fields{baseAddress: srv.URL},
+			want:    []want{},
 			wantErr: false,
 		},
 	}
@@ -101,26 +106,98 @@ func TestTx_GetByHashList(t *testing.T) {
 			client := NewClient(tt.fields.baseAddress)
 			got, err := client.Tx.GetByHashList(tt.args.hashes)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("GetListByAddress() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("GetByHashList() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			/*if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("GetListByAddress() got = %v, want %v", got, tt.want)
-			}*/
-			t.Logf("%+v", got)
+			gotShort := make([]want, 0)
+			for _, txr := range got {
+				if txr.Block.IsEmpty() {
+					t.Errorf("GetByHashList() empty block of %s", txr.Hash)
+				}
+				gotShort = append(gotShort, want{hash: txr.Hash, confirmed: txr.Confirmed})
+			}
+			if !reflect.DeepEqual(gotShort, tt.want) {
+				t.Errorf("GetByHashList() got = %v, want %v", gotShort, tt.want)
+			}
+		})
+	}
+}
+
+func TestTx_LookupHashList(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
+	all, err := NewClient(srv.URL).Tx.GetHashListByHeight(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unknown := crypto.DHASH([]byte("unknown"))
+	type args struct {
+		hashes []crypto.Hash
+		chunk  int
+	}
+	tests := []struct {
+		name        string
+		args        args
+		wantHashes  []crypto.Hash
+		wantMissing []crypto.Hash
+		wantErr     bool
+	}{
+		{
+			name:        "single chunk",
+			args:        args{hashes: []crypto.Hash{all[2], all[0], all[1]}, chunk: DefaultHashListChunkSize},
+			wantHashes:  []crypto.Hash{all[2], all[0], all[1]},
+			wantMissing: []crypto.Hash{},
+			wantErr:     false,
+		},
+		{
+			name:        "chunk per hash with missing",
+			args:        args{hashes: []crypto.Hash{all[1], unknown, all[2], all[0]}, chunk: 1},
+			wantHashes:  []crypto.Hash{all[1], all[2], all[0]},
+			wantMissing: []crypto.Hash{unknown},
+			wantErr:     false,
+		},
+		{
+			name:        "duplicates",
+			args:        args{hashes: []crypto.Hash{all[0], all[0], all[1]}, chunk: 2},
+			wantHashes:  []crypto.Hash{all[0], all[1]},
+			wantMissing: []crypto.Hash{},
+			wantErr:     false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient(srv.URL)
+			client.Tx.HashListChunk = tt.args.chunk
+			got, err := client.Tx.LookupHashList(tt.args.hashes)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("LookupHashList() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			gotHashes := make([]crypto.Hash, 0)
+			for _, txr := range got.Transactions {
+				gotHashes = append(gotHashes, txr.Hash)
+			}
+			if !reflect.DeepEqual(gotHashes, tt.wantHashes) {
+				t.Errorf("LookupHashList() got = %v, want %v", gotHashes, tt.wantHashes)
+			}
+			if !reflect.DeepEqual(got.Missing, tt.wantMissing) {
+				t.Errorf("LookupHashList() missing = %v, want %v", got.Missing, tt.wantMissing)
+			}
 		})
 	}
 }
 
 func TestTx_Validate(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
 	type fields struct {
 		baseAddress string
 	}
 	type args struct {
 		privateKey string
 		toAddress  string
-		amount     uint64
-		commission uint64
+		amount     crypto.Amount
+		commission crypto.Amount
 	}
 	tests := []struct {
 		name    string
@@ -131,7 +208,7 @@ func TestTx_Validate(t *testing.T) {
 	}{
 		{
 			name:   "Normal test",
-			fields: fields{baseAddress: Url},
+			fields: fields{baseAddress: srv.URL},
 			args: args{
 				privateKey: Pk,
 				toAddress:  "VLa1hi77ZXD2BSWDD9wQe8vAhejXyS7vBM4",
@@ -169,14 +246,16 @@ func TestTx_Validate(t *testing.T) {
 }
 
 func TestTx_Publish(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
 	type fields struct {
 		baseAddress string
 	}
 	type args struct {
 		privateKey   string
 		toAddress    string
-		amount       uint64
-		commission   uint64
+		amount       crypto.Amount
+		commission   crypto.Amount
 		nodeIDString string
 	}
 	tests := []struct {
@@ -188,7 +267,7 @@ func TestTx_Publish(t *testing.T) {
 	}{
 		{
 			name:   "Normal test",
-			fields: fields{baseAddress: TestNetUrl},
+			fields: fields{baseAddress: srv.URL},
 			args: args{
 				privateKey:   Pk,
 				toAddress:    "VLa1hi77ZXD2BSWDD9wQe8vAhejXyS7vBM4",
@@ -231,7 +310,100 @@ func TestTx_Publish(t *testing.T) {
 	}
 }
 
+func TestTx_PublishAndWait(t *testing.T) {
+	const to = "VLa1hi77ZXD2BSWDD9wQe8vAhejXyS7vBM4"
+	type args struct {
+		confirmations uint32
+		timeout       time.Duration
+	}
+	tests := []struct {
+		name          string
+		args          args
+		node          func(srv *velastest.Server, hash crypto.Hash, done chan struct{})
+		wantConfirmed uint32
+		wantErr       error
+	}{
+		{
+			name: "Confirmed",
+			args: args{confirmations: 3, timeout: 5 * time.Second},
+			node: func(srv *velastest.Server, hash crypto.Hash, done chan struct{}) {
+				for {
+					select {
+					case <-done:
+						return
+					case <-time.After(5 * time.Millisecond):
+						srv.Mine()
+					}
+				}
+			},
+			wantConfirmed: 3,
+			wantErr:       nil,
+		},
+		{
+			name: "Evicted",
+			args: args{confirmations: 1, timeout: 5 * time.Second},
+			node: func(srv *velastest.Server, hash crypto.Hash, done chan struct{}) {
+				_ = srv.Evict(hash)
+			},
+			wantErr: ErrTxEvicted,
+		},
+		{
+			name: "Timeout",
+			args: args{confirmations: 1, timeout: 50 * time.Millisecond},
+			node: func(srv *velastest.Server, hash crypto.Hash, done chan struct{}) {
+			},
+			wantConfirmed: 0,
+			wantErr:       ErrTxTimeout,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newTestServer(t)
+			defer srv.Close()
+			client := NewClient(srv.URL)
+			client.Tx.WaitPollInterval = 10 * time.Millisecond
+			tx := buildTestTx(t, client, Pk, to, 1000)
+			hash := tx.Hash
+
+			// node acts after publish, which is done before the first check
+			done := make(chan struct{})
+			defer close(done)
+			go func() {
+				for {
+					if found, _ := client.Tx.LookupHashList([]crypto.Hash{hash}); found != nil && len(found.Transactions) > 0 {
+						tt.node(srv, hash, done)
+						return
+					}
+					select {
+					case <-done:
+						return
+					case <-time.After(time.Millisecond):
+					}
+				}
+			}()
+
+			ctx, cancel := context.WithTimeout(context.Background(), tt.args.timeout)
+			defer cancel()
+			got, err := client.Tx.PublishAndWait(ctx, *tx, tt.args.confirmations)
+			if err != tt.wantErr {
+				t.Fatalf("PublishAndWait() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got == nil || got.Result != hash.String() {
+				t.Fatalf("PublishAndWait() got = %+v, want result %s", got, hash)
+			}
+			if tt.wantErr == ErrTxEvicted {
+				return
+			}
+			if got.Tx == nil || got.Tx.Confirmed < tt.wantConfirmed {
+				t.Errorf("PublishAndWait() tx = %+v, want %d confirmations", got.Tx, tt.wantConfirmed)
+			}
+		})
+	}
+}
+
 func TestTx_MakeStake(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
 	hd, _ := crypto.HDFromPrivateKeyHex(Pk2)
 	wallet, _ := hd.ToWallet()
 	returnAddress := wallet.Base58Address
@@ -246,7 +418,7 @@ func TestTx_MakeStake(t *testing.T) {
 	}
 	type args struct {
 		privateKey string
-		commission uint64
+		commission crypto.Amount
 		receivers  []crypto.Receiver
 	}
 	tests := []struct {
@@ -258,7 +430,7 @@ func TestTx_MakeStake(t *testing.T) {
 	}{
 		{
 			name:   "Staking test",
-			fields: fields{baseAddress: LocalUrl},
+			fields: fields{baseAddress: srv.URL},
 			args: args{
 				privateKey: Pk2,
 				commission: 1000000,
@@ -310,6 +482,8 @@ func TestTx_MakeStake(t *testing.T) {
 }
 
 func TestTx_GetHashListByHeight(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
 	type fields struct {
 		baseAddress string
 	}
@@ -320,12 +494,12 @@ func TestTx_GetHashListByHeight(t *testing.T) {
 		name    string
 		fields  fields
 		args    args
-		want    []string
+		want    []crypto.Hash
 		wantErr bool
 	}{
 		{
 			name:    "Correct",
-			fields:  fields{baseAddress: Url},
+			fields:  fields{baseAddress: srv.URL},
 			args:    args{height: 209126},
 			want:    nil,
 			wantErr: false,
@@ -345,3 +519,96 @@ func TestTx_GetHashListByHeight(t *testing.T) {
 		})
 	}
 }
+
+func TestTx_Validate_AllFunds(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
+	rates := crypto.FeeRates{Base: 1000, PerByte: 10, PerInput: 100, PerOutput: 50, Min: 5000}
+	srv.SetFeeRates(rates)
+	client := NewClient(srv.URL)
+
+	// transaction with all funds minus estimated commission is accepted by node with the same rates
+	hd, _ := crypto.HDFromPrivateKeyHex(Pk)
+	wallet, _ := hd.ToWallet()
+	unspents, _ := client.Wallet.GetUnspent(wallet.Base58Address)
+	tx, err := crypto.NewTransactionAllFunds(unspents, *hd, "VLa1hi77ZXD2BSWDD9wQe8vAhejXyS7vBM4", rates)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Tx.Validate(*tx); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	tx.Outputs[0].Value--
+	tx.Outputs[1].Value++
+	if err := client.Tx.Validate(*tx); err == nil {
+		t.Errorf("Validate() of transaction with smaller commission must fail")
+	}
+}
+
+func TestTx_PublishAndWait_Delayed(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
+	srv.SetAutoMine(true)
+	nodeURL, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// node doesn't return transaction in the first lookups after publish
+	proxy := httputil.NewSingleHostReverseProxy(nodeURL)
+	lookups := int32(0)
+	delayed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/txs" && atomic.AddInt32(&lookups, 1) <= DefaultWaitGracePolls {
+			_, _ = w.Write([]byte("[]"))
+			return
+		}
+		proxy.ServeHTTP(w, r)
+	}))
+	defer delayed.Close()
+
+	client := NewClient(delayed.URL)
+	client.Tx.WaitPollInterval = 10 * time.Millisecond
+	tx := buildTestTx(t, client, Pk, "VLa1hi77ZXD2BSWDD9wQe8vAhejXyS7vBM4", 1000)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	got, err := client.Tx.PublishAndWait(ctx, *tx, 1)
+	if err != nil {
+		t.Fatalf("PublishAndWait() error = %v", err)
+	}
+	if got.Tx == nil || got.Tx.Confirmed != 1 {
+		t.Errorf("PublishAndWait() got = %+v", got.Tx)
+	}
+
+	// node misses the transaction once after it was seen
+	seen := NewClient(srv.URL)
+	tx = buildTestTx(t, seen, Pk, "VLa1hi77ZXD2BSWDD9wQe8vAhejXyS7vBM4", 1000)
+	srv.SetAutoMine(false)
+	lagging := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/txs" && atomic.AddInt32(&lookups, 1) == 2 {
+			srv.Mine()
+			_, _ = w.Write([]byte("[]"))
+			return
+		}
+		proxy.ServeHTTP(w, r)
+	}))
+	defer lagging.Close()
+	atomic.StoreInt32(&lookups, 0)
+	client = NewClient(lagging.URL)
+	client.Tx.WaitPollInterval = 10 * time.Millisecond
+	got, err = client.Tx.PublishAndWait(ctx, *tx, 1)
+	if err != nil {
+		t.Fatalf("PublishAndWait() with a miss after the transaction was seen error = %v", err)
+	}
+	if got.Tx == nil || got.Tx.Confirmed != 1 {
+		t.Errorf("PublishAndWait() got = %+v", got.Tx)
+	}
+
+	srv.SetAutoMine(true)
+	client = NewClient(delayed.URL)
+	client.Tx.WaitPollInterval = 10 * time.Millisecond
+	client.Tx.WaitGracePolls = 0
+	atomic.StoreInt32(&lookups, 0)
+	tx = buildTestTx(t, client, Pk, "VLa1hi77ZXD2BSWDD9wQe8vAhejXyS7vBM4", 1000)
+	if _, err := client.Tx.PublishAndWait(ctx, *tx, 1); err != ErrTxEvicted {
+		t.Errorf("PublishAndWait() without grace polls error = %v, want %v", err, ErrTxEvicted)
+	}
+}
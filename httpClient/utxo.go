@@ -0,0 +1,254 @@
+package httpClient
+
+import (
+	"github.com/go-errors/errors"
+	"github.com/velas/GoVelas/crypto"
+	"sync"
+	"time"
+)
+
+// Default time of keeping unspents of address before new request
+const DefaultUTXOCacheTTL = 30 * time.Second
+
+// Default time, after which reserved outputs are available again, if reservation is not committed or released
+const DefaultReservationTTL = 5 * time.Minute
+
+// Error of Reserve, when not reserved outputs don't cover the target
+var ErrNotEnoughUnspents = errors.New("not enough unreserved unspent outputs")
+
+// key of outpoint in manager maps
+type outpointKey struct {
+	hash  crypto.Hash
+	index uint32
+}
+
+// lock of reserved outpoint
+type reservationLock struct {
+	id      uint64
+	expires time.Time
+}
+
+// cached unspents of address
+type unspentCache struct {
+	unspents  []crypto.TransactionInputOutpoint
+	fetchedAt time.Time
+}
+
+// Options of UTXO manager
+type UTXOManagerOptions struct {
+	CacheTTL       time.Duration // DefaultUTXOCacheTTL if zero
+	ReservationTTL time.Duration // DefaultReservationTTL if zero
+}
+
+// UTXOManager prevents spending the same outputs by concurrent transactions. It caches unspents of addresses, locks
+// outputs while transaction is built and published, and hides outputs spent by published transactions until node
+// stops returning them
+type UTXOManager struct {
+	wallet   *Wallet
+	opts     UTXOManagerOptions
+	now      func() time.Time
+	mu       sync.Mutex
+	cache    map[string]*unspentCache
+	reserved map[outpointKey]reservationLock
+	spent    map[outpointKey]bool
+	lastID   uint64
+}
+
+// Create UTXO manager, which requests unspents with wallet client
+func (cl *Client) NewUTXOManager(opts UTXOManagerOptions) *UTXOManager {
+	if opts.CacheTTL <= 0 {
+		opts.CacheTTL = DefaultUTXOCacheTTL
+	}
+	if opts.ReservationTTL <= 0 {
+		opts.ReservationTTL = DefaultReservationTTL
+	}
+	return &UTXOManager{
+		wallet:   cl.Wallet,
+		opts:     opts,
+		now:      time.Now,
+		cache:    make(map[string]*unspentCache),
+		reserved: make(map[outpointKey]reservationLock),
+		spent:    make(map[outpointKey]bool),
+	}
+}
+
+// Outputs, locked by UTXO manager for one transaction
+type Reservation struct {
+	Address   string
+	Outpoints []crypto.TransactionInputOutpoint
+	m         *UTXOManager
+	id        uint64
+	done      bool
+}
+
+// Reserve choose outputs of address for spending target amount(amount with commission) and lock them
+func (m *UTXOManager) Reserve(address string, target crypto.Amount) (*Reservation, error) {
+	available, err := m.Available(address)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	// outputs could be reserved by another goroutine after Available
+	free := make([]crypto.TransactionInputOutpoint, 0, len(available))
+	for _, unspent := range available {
+		if m.isFree(keyOf(unspent)) {
+			free = append(free, unspent)
+		}
+	}
+	selected, err := crypto.SelectUnspents(free, target)
+	if err != nil {
+		return nil, ErrNotEnoughUnspents
+	}
+	m.lastID++
+	lock := reservationLock{id: m.lastID, expires: m.now().Add(m.opts.ReservationTTL)}
+	for _, unspent := range selected {
+		m.reserved[keyOf(unspent)] = lock
+	}
+	return &Reservation{
+		Address:   address,
+		Outpoints: selected,
+		m:         m,
+		id:        lock.id,
+	}, nil
+}
+
+// ReserveAll lock all unreserved outputs of address, reservation can be empty
+func (m *UTXOManager) ReserveAll(address string) (*Reservation, error) {
+	available, err := m.Available(address)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastID++
+	lock := reservationLock{id: m.lastID, expires: m.now().Add(m.opts.ReservationTTL)}
+	selected := make([]crypto.TransactionInputOutpoint, 0, len(available))
+	for _, unspent := range available {
+		key := keyOf(unspent)
+		if m.isFree(key) {
+			m.reserved[key] = lock
+			selected = append(selected, unspent)
+		}
+	}
+	return &Reservation{
+		Address:   address,
+		Outpoints: selected,
+		m:         m,
+		id:        lock.id,
+	}, nil
+}
+
+// Available return unspents of address, which are not reserved and not spent locally
+func (m *UTXOManager) Available(address string) ([]crypto.TransactionInputOutpoint, error) {
+	m.mu.Lock()
+	cache, ok := m.cache[address]
+	fresh := ok && m.now().Sub(cache.fetchedAt) < m.opts.CacheTTL
+	m.mu.Unlock()
+	if !fresh {
+		if err := m.Refresh(address); err != nil {
+			return nil, err
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	result := make([]crypto.TransactionInputOutpoint, 0)
+	for _, unspent := range m.cache[address].unspents {
+		if m.isFree(keyOf(unspent)) {
+			result = append(result, unspent)
+		}
+	}
+	return result, nil
+}
+
+// Refresh request unspents of address from node. Outputs marked as spent are forgotten, when node doesn't return them
+func (m *UTXOManager) Refresh(address string) error {
+	unspents, err := m.wallet.GetUnspent(address)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	returned := make(map[outpointKey]bool, len(unspents))
+	for _, unspent := range unspents {
+		returned[keyOf(unspent)] = true
+	}
+	if old, ok := m.cache[address]; ok {
+		for _, unspent := range old.unspents {
+			key := keyOf(unspent)
+			if !returned[key] {
+				delete(m.spent, key)
+				delete(m.reserved, key)
+			}
+		}
+	}
+	m.cache[address] = &unspentCache{unspents: unspents, fetchedAt: m.now()}
+	return nil
+}
+
+// isFree check that outpoint is not spent and not reserved, expired reservations are removed
+func (m *UTXOManager) isFree(key outpointKey) bool {
+	if m.spent[key] {
+		return false
+	}
+	lock, ok := m.reserved[key]
+	if !ok {
+		return true
+	}
+	if !m.now().Before(lock.expires) {
+		delete(m.reserved, key)
+		return true
+	}
+	return false
+}
+
+// Release unlock outputs, when transaction is not published
+func (r *Reservation) Release() {
+	r.finish(false)
+}
+
+// Commit mark outputs as spent, when transaction is published
+func (r *Reservation) Commit() {
+	r.finish(true)
+}
+
+// finishPublish finish reservation by result of publishTx. Reservation is committed, if transaction is published and
+// waiting succeeded, and it is released, if transaction isn't published or node evicted it. If state of published
+// transaction is unknown, for example after ErrTxTimeout, reservation is kept, so its outputs are available again
+// after ReservationTTL, if node still returns them
+func (r *Reservation) finishPublish(result *SendResult, err error) {
+	switch {
+	case result == nil || err == ErrTxEvicted:
+		r.Release()
+	case err == nil:
+		r.Commit()
+	}
+}
+
+// finish reservation, outputs reserved again by another reservation after expiration are not touched
+func (r *Reservation) finish(spent bool) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	if r.done {
+		return
+	}
+	r.done = true
+	for _, unspent := range r.Outpoints {
+		key := keyOf(unspent)
+		if lock, ok := r.m.reserved[key]; ok && lock.id == r.id {
+			delete(r.m.reserved, key)
+		}
+		if spent {
+			r.m.spent[key] = true
+		}
+	}
+}
+
+// keyOf return map key of outpoint
+func keyOf(unspent crypto.TransactionInputOutpoint) outpointKey {
+	return outpointKey{hash: unspent.Hash, index: unspent.Index}
+}
@@ -0,0 +1,124 @@
+package httpClient
+
+import (
+	"context"
+	"github.com/velas/GoVelas/crypto"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestUTXOManager_Reserve(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
+	client := NewClient(srv.URL)
+	hd, _ := crypto.HDFromPrivateKeyHex(Pk)
+	wallet, _ := hd.ToWallet()
+	m := client.NewUTXOManager(UTXOManagerOptions{CacheTTL: time.Hour, ReservationTTL: time.Minute})
+	now := time.Now()
+	m.now = func() time.Time { return now }
+
+	first, err := m.Reserve(wallet.Base58Address, 50000000)
+	if err != nil {
+		t.Fatalf("Reserve() error = %v", err)
+	}
+	second, err := m.Reserve(wallet.Base58Address, 50000000)
+	if err != nil {
+		t.Fatalf("Reserve() error = %v", err)
+	}
+	if first.Outpoints[0] == second.Outpoints[0] {
+		t.Fatalf("Reserve() returned the same outpoint twice: %v", first.Outpoints[0])
+	}
+	if _, err := m.Reserve(wallet.Base58Address, 1); err != ErrNotEnoughUnspents {
+		t.Fatalf("Reserve() error = %v, want ErrNotEnoughUnspents", err)
+	}
+
+	first.Release()
+	third, err := m.Reserve(wallet.Base58Address, 1)
+	if err != nil || third.Outpoints[0] != first.Outpoints[0] {
+		t.Fatalf("Reserve() after release got = %v, error = %v", third, err)
+	}
+
+	// second reservation expires, third is committed
+	now = now.Add(2 * time.Minute)
+	third.Commit()
+	available, err := m.Available(wallet.Base58Address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(available) != 1 || available[0] != second.Outpoints[0] {
+		t.Errorf("Available() got = %v, want expired %v", available, second.Outpoints[0])
+	}
+	// late finish of expired reservation doesn't unlock new one
+	fourth, err := m.Reserve(wallet.Base58Address, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second.Release()
+	if available, _ := m.Available(wallet.Base58Address); len(available) != 0 {
+		t.Errorf("Available() got = %v, want %v reserved", available, fourth.Outpoints)
+	}
+
+	// spent output is hidden until node stops returning it
+	if err := m.Refresh(wallet.Base58Address); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m.spent[keyOf(third.Outpoints[0])]; !ok {
+		t.Errorf("Refresh() forgot spent output, which node still returns")
+	}
+}
+
+func TestUTXOManager_ConcurrentSend(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
+	client := NewClient(srv.URL)
+	hd, _ := crypto.HDFromPrivateKeyHex(Pk)
+	m := client.NewUTXOManager(UTXOManagerOptions{})
+
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = client.Send(context.Background(), *hd, "VLa1hi77ZXD2BSWDD9wQe8vAhejXyS7vBM4", 1000,
+				SendOptions{UTXO: m})
+		}(i)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("Send() %d error = %v", i, err)
+		}
+	}
+	if _, err := client.Send(context.Background(), *hd, "VLa1hi77ZXD2BSWDD9wQe8vAhejXyS7vBM4", 1000,
+		SendOptions{UTXO: m}); err != ErrNotEnoughUnspents {
+		t.Errorf("Send() error = %v, want ErrNotEnoughUnspents", err)
+	}
+
+	// output spent outside of manager is selected, node rejects transaction and reservation is released
+	srv.Mine()
+	wallet, _ := hd.ToWallet()
+	if err := m.Refresh(wallet.Base58Address); err != nil {
+		t.Fatal(err)
+	}
+	unspents, _ := client.Wallet.GetUnspent(wallet.Base58Address)
+	smallest := unspents[0]
+	for _, unspent := range unspents {
+		if unspent.Value < smallest.Value {
+			smallest = unspent
+		}
+	}
+	tx, _ := crypto.NewTransaction([]crypto.TransactionInputOutpoint{smallest}, 1000, *hd, wallet.Base58Address,
+		"VLa1hi77ZXD2BSWDD9wQe8vAhejXyS7vBM4", 1000000, crypto.NodeID{})
+	if err := client.Tx.Publish(*tx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Send(context.Background(), *hd, "VLa1hi77ZXD2BSWDD9wQe8vAhejXyS7vBM4", 1000,
+		SendOptions{UTXO: m}); err == nil {
+		t.Fatal("Send() of spent output must fail on validation")
+	}
+	if len(m.reserved) != 0 {
+		t.Errorf("Send() didn't release reservation: %v", m.reserved)
+	}
+}
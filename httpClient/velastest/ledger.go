@@ -0,0 +1,421 @@
+package velastest
+
+import (
+	"bytes"
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/go-errors/errors"
+	"github.com/velas/GoVelas/crypto"
+	"github.com/velas/GoVelas/crypto/helpers"
+	"sort"
+	"sync"
+	"time"
+)
+
+// outpoint key of unspent output
+type outpoint struct {
+	hash  crypto.Hash
+	index uint32
+}
+
+// unspent output with order of creation, used for stable responses
+type utxo struct {
+	output crypto.TransactionOutput
+	seq    uint64
+}
+
+// transaction stored in ledger, block is nil while transaction is in mempool
+type txEntry struct {
+	tx    *crypto.Tx
+	block *block
+}
+
+// mined block
+type block struct {
+	hash       crypto.Hash
+	height     int
+	nonce      uint32
+	prevBlock  crypto.Hash
+	merkleRoot crypto.Hash
+	timestamp  uint32
+	signature  []byte
+	txs        []*crypto.Tx
+}
+
+// ledger is the UTXO state of fake node
+type ledger struct {
+	mu       sync.Mutex
+	utxos    map[outpoint]utxo
+	spent    map[outpoint]crypto.Hash // outpoints spent by mempool transactions
+	txs      map[crypto.Hash]*txEntry
+	mempool  []*crypto.Tx
+	blocks   []*block
+	byHash   map[crypto.Hash]*block
+	addrTxs  map[string][]crypto.Hash
+	seq      uint64
+	nonce    uint32
+	autoMine bool
+	rates    crypto.FeeRates
+	producer *crypto.HD // key, which signs blocks
+}
+
+// create ledger with genesis block
+func newLedger() (*ledger, error) {
+	producer, err := crypto.GenerateHD()
+	if err != nil {
+		return nil, err
+	}
+	l := &ledger{
+		utxos:    make(map[outpoint]utxo),
+		spent:    make(map[outpoint]crypto.Hash),
+		txs:      make(map[crypto.Hash]*txEntry),
+		byHash:   make(map[crypto.Hash]*block),
+		addrTxs:  make(map[string][]crypto.Hash),
+		rates:    crypto.DefaultFeeRates,
+		producer: producer,
+	}
+	l.mine()
+	return l, nil
+}
+
+// tip return last mined block
+func (l *ledger) tip() *block {
+	return l.blocks[len(l.blocks)-1]
+}
+
+// fund mine block with a transaction without inputs, which send amount to address. Pending transactions are mined
+// in the same block
+func (l *ledger) fund(address string, amount uint64) (crypto.TransactionInputOutpoint, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !crypto.IsWalletAddress(address) {
+		return crypto.TransactionInputOutpoint{}, errors.Errorf("invalid address %s", address)
+	}
+	tx := &crypto.Tx{
+		Version:  1,
+		LockTime: uint32(len(l.blocks)),
+		Outputs: []crypto.TransactionOutput{
+			{
+				Index:         0,
+				Value:         amount,
+				Script:        base58.Decode(address),
+				WalletAddress: base58.Decode(address),
+			},
+		},
+	}
+	tx.Hash = tx.GenerateHash()
+	l.addTx(tx)
+	l.mine()
+	return crypto.TransactionInputOutpoint{Hash: tx.Hash, Index: 0, Value: amount}, nil
+}
+
+// validate check transaction against current state, without changing it
+func (l *ledger) validate(tx *crypto.Tx) error {
+	if _, ok := l.txs[tx.Hash]; ok {
+		return errors.Errorf("transaction %x already exists", tx.Hash)
+	}
+	if len(tx.Inputs) == 0 {
+		return errors.Errorf("transaction without inputs")
+	}
+	if err := tx.Verify(); err != nil {
+		return err
+	}
+	if err := tx.CheckPayloads(); err != nil {
+		return err
+	}
+	// finality rule of node is assumed to be the same, see crypto.LockTimeThreshold
+	if !tx.IsFinal(uint32(len(l.blocks)), uint32(time.Now().Unix())) {
+		return errors.Errorf("transaction is locked until %d", tx.LockTime)
+	}
+
+	totalIn := crypto.Amount(0)
+	used := make(map[outpoint]bool)
+	for i, txIn := range tx.Inputs {
+		key := outpoint{hash: txIn.PreviousOutput.Hash, index: txIn.PreviousOutput.Index}
+		if used[key] {
+			return errors.Errorf("input %d spends the same output twice", i)
+		}
+		used[key] = true
+		u, ok := l.utxos[key]
+		if !ok {
+			return errors.Errorf("input %d refers to unknown or spent output %x:%d", i, key.hash, key.index)
+		}
+		if _, ok := l.spent[key]; ok {
+			return errors.Errorf("input %d refers to output %x:%d, spent by pending transaction", i, key.hash, key.index)
+		}
+		if u.output.Value != txIn.PreviousOutput.Value {
+			return errors.Errorf("input %d value %d, output value %d", i, txIn.PreviousOutput.Value, u.output.Value)
+		}
+		wallet, err := crypto.CreateWallet(txIn.PublicKey)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(wallet.Address, u.output.Script) {
+			return errors.Errorf("input %d is not signed by owner of output", i)
+		}
+		if totalIn, err = totalIn.Add(u.output.Amount()); err != nil {
+			return err
+		}
+	}
+
+	totalOut := crypto.Amount(0)
+	for i, txOut := range tx.Outputs {
+		if txOut.Index != uint32(i) {
+			return errors.Errorf("output %d has index %d", i, txOut.Index)
+		}
+		if len(txOut.Script) > 0 && !crypto.IsWalletAddress(base58.Encode(txOut.Script)) {
+			return errors.Errorf("output %d has invalid address", i)
+		}
+		var err error
+		if totalOut, err = totalOut.Add(txOut.Amount()); err != nil {
+			return err
+		}
+	}
+	if totalIn != totalOut {
+		return errors.Errorf("inputs amount %d not equal outputs amount %d", totalIn, totalOut)
+	}
+	if len(tx.Outputs) == 0 || len(tx.Outputs[0].Script) != 0 {
+		return errors.Errorf("first output must be commission")
+	}
+	commission, err := l.rates.ForTx(tx)
+	if err != nil {
+		return err
+	}
+	if tx.Outputs[0].Amount() < commission {
+		return errors.Errorf("commission %d is less than required %d", tx.Outputs[0].Value, commission)
+	}
+	return nil
+}
+
+// publish validate transaction and add it to mempool
+func (l *ledger) publish(tx *crypto.Tx) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := l.validate(tx); err != nil {
+		return err
+	}
+	for _, txIn := range tx.Inputs {
+		l.spent[outpoint{hash: txIn.PreviousOutput.Hash, index: txIn.PreviousOutput.Index}] = tx.Hash
+	}
+	l.addTx(tx)
+	if l.autoMine {
+		l.mine()
+	}
+	return nil
+}
+
+// addTx add transaction to mempool without validation
+func (l *ledger) addTx(tx *crypto.Tx) {
+	l.mempool = append(l.mempool, tx)
+	l.txs[tx.Hash] = &txEntry{tx: tx}
+	l.indexAddresses(tx)
+}
+
+// indexAddresses remember transaction for every address of inputs and outputs
+func (l *ledger) indexAddresses(tx *crypto.Tx) {
+	seen := make(map[string]bool)
+	addresses := make([]string, 0)
+	for _, txIn := range tx.Inputs {
+		if len(txIn.WalletAddress) > 0 {
+			addresses = append(addresses, base58.Encode(txIn.WalletAddress))
+		}
+	}
+	for _, txOut := range tx.Outputs {
+		if len(txOut.Script) > 0 {
+			addresses = append(addresses, base58.Encode(txOut.Script))
+		}
+	}
+	for _, address := range addresses {
+		if seen[address] {
+			continue
+		}
+		seen[address] = true
+		l.addrTxs[address] = append(l.addrTxs[address], tx.Hash)
+	}
+}
+
+// mine create block from mempool transactions
+func (l *ledger) mine() *block {
+	l.nonce++
+	blk := &block{
+		height:    len(l.blocks),
+		nonce:     l.nonce,
+		timestamp: uint32(time.Now().Unix()),
+		txs:       l.mempool,
+	}
+	if len(l.blocks) > 0 {
+		blk.prevBlock = l.tip().hash
+	}
+	// merkle tree of node is not known, root of fake node is hash of all transaction hashes
+	hashes := make([]byte, 0, len(blk.txs)*len(crypto.Hash{}))
+	for _, tx := range blk.txs {
+		hashes = append(hashes, tx.Hash[:]...)
+	}
+	blk.merkleRoot = crypto.DHASH(hashes)
+	header := blockHeader(blk)
+	header.Hash = headerHash(header)
+	if err := header.Sign(*l.producer); err != nil {
+		panic(err)
+	}
+	blk.hash = header.Hash
+	blk.signature = header.Script
+
+	for _, tx := range blk.txs {
+		for _, txIn := range tx.Inputs {
+			key := outpoint{hash: txIn.PreviousOutput.Hash, index: txIn.PreviousOutput.Index}
+			delete(l.utxos, key)
+			delete(l.spent, key)
+		}
+		for _, txOut := range tx.Outputs {
+			if len(txOut.Script) == 0 {
+				// commission output can't be spent
+				continue
+			}
+			l.seq++
+			l.utxos[outpoint{hash: tx.Hash, index: txOut.Index}] = utxo{output: txOut, seq: l.seq}
+		}
+		l.txs[tx.Hash].block = blk
+	}
+
+	l.mempool = nil
+	l.blocks = append(l.blocks, blk)
+	l.byHash[blk.hash] = blk
+	return blk
+}
+
+// rollback remove last blocks from chain and return their transactions to mempool, genesis block can't be removed
+func (l *ledger) rollback(depth int) error {
+	if depth <= 0 || depth >= len(l.blocks) {
+		return errors.Errorf("invalid rollback depth %d, height %d", depth, l.tip().height)
+	}
+	returned := make([]*crypto.Tx, 0)
+	for i := 0; i < depth; i++ {
+		blk := l.tip()
+		for j := len(blk.txs) - 1; j >= 0; j-- {
+			tx := blk.txs[j]
+			for _, txOut := range tx.Outputs {
+				delete(l.utxos, outpoint{hash: tx.Hash, index: txOut.Index})
+			}
+			for _, txIn := range tx.Inputs {
+				prev := l.txs[txIn.PreviousOutput.Hash].tx
+				l.seq++
+				l.utxos[outpoint{hash: prev.Hash, index: txIn.PreviousOutput.Index}] = utxo{
+					output: prev.Outputs[txIn.PreviousOutput.Index],
+					seq:    l.seq,
+				}
+			}
+			l.txs[tx.Hash].block = nil
+		}
+		returned = append(append([]*crypto.Tx{}, blk.txs...), returned...)
+		// orphaned block is still available by hash, like on real node
+		l.blocks = l.blocks[:len(l.blocks)-1]
+	}
+
+	for _, tx := range returned {
+		for _, txIn := range tx.Inputs {
+			l.spent[outpoint{hash: txIn.PreviousOutput.Hash, index: txIn.PreviousOutput.Index}] = tx.Hash
+		}
+	}
+	l.mempool = append(returned, l.mempool...)
+	return nil
+}
+
+// evict remove pending transaction from mempool and forget it
+func (l *ledger) evict(hash crypto.Hash) error {
+	entry, ok := l.txs[hash]
+	if !ok || entry.block != nil {
+		return errors.Errorf("transaction %x is not in mempool", hash)
+	}
+	for i, tx := range l.mempool {
+		if tx.Hash == hash {
+			l.mempool = append(l.mempool[:i:i], l.mempool[i+1:]...)
+			break
+		}
+	}
+	for _, txIn := range entry.tx.Inputs {
+		delete(l.spent, outpoint{hash: txIn.PreviousOutput.Hash, index: txIn.PreviousOutput.Index})
+	}
+	for address, hashes := range l.addrTxs {
+		for i, addrHash := range hashes {
+			if addrHash == hash {
+				l.addrTxs[address] = append(hashes[:i:i], hashes[i+1:]...)
+				break
+			}
+		}
+	}
+	delete(l.txs, hash)
+	return nil
+}
+
+// unspents return unspent outputs of address, staking outputs included only if withStakes is true
+func (l *ledger) unspents(address string, withStakes bool) []crypto.TransactionInputOutpoint {
+	script := base58.Decode(address)
+	found := make([]outpoint, 0)
+	for key, u := range l.utxos {
+		if !bytes.Equal(u.output.Script, script) {
+			continue
+		}
+		if !withStakes && !u.output.NodeID.IsEmpty() {
+			continue
+		}
+		if _, ok := l.spent[key]; ok {
+			continue
+		}
+		found = append(found, key)
+	}
+	sort.Slice(found, func(i, j int) bool {
+		return l.utxos[found[i]].seq < l.utxos[found[j]].seq
+	})
+
+	result := make([]crypto.TransactionInputOutpoint, 0, len(found))
+	for _, key := range found {
+		result = append(result, crypto.TransactionInputOutpoint{
+			Hash:  key.hash,
+			Index: key.index,
+			Value: l.utxos[key].output.Value,
+		})
+	}
+	return result
+}
+
+// confirmations return count of blocks, included block of transaction
+func (l *ledger) confirmations(blk *block) uint32 {
+	if blk == nil {
+		return 0
+	}
+	return uint32(l.tip().height - blk.height + 1)
+}
+
+// headerHash return hash of header fields in the own layout of fake node, header layout of the real node is not known
+func headerHash(header crypto.BlockHeader) crypto.Hash {
+	return crypto.DHASH(helpers.ConcatByteArray([][]byte{
+		helpers.UInt32ToBytes(header.Type),
+		helpers.UInt32ToBytes(header.Version),
+		helpers.UInt32ToBytes(header.Height),
+		header.PrevBlock[:],
+		header.MerkleRoot[:],
+		helpers.UInt32ToBytes(header.Timestamp),
+		helpers.UInt32ToBytes(header.Bits),
+		helpers.UInt32ToBytes(header.Nonce),
+		helpers.UInt32ToBytes(header.TxnCount),
+		helpers.UInt32ToBytes(header.AdviceCount),
+		header.Seed,
+	}))
+}
+
+// blockHeader return typed header of block, hash and signature are copied from block
+func blockHeader(blk *block) crypto.BlockHeader {
+	return crypto.BlockHeader{
+		Hash:        blk.hash,
+		Height:      uint32(blk.height),
+		Version:     1,
+		PrevBlock:   blk.prevBlock,
+		MerkleRoot:  blk.merkleRoot,
+		Timestamp:   blk.timestamp,
+		Nonce:       blk.nonce,
+		TxnCount:    uint32(len(blk.txs)),
+		AdviceCount: 1,
+		Script:      blk.signature,
+	}
+}
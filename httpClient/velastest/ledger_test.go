@@ -0,0 +1,197 @@
+package velastest
+
+import (
+	"testing"
+
+	"github.com/velas/GoVelas/crypto"
+)
+
+func TestLedger_Publish(t *testing.T) {
+	const to = "VLa1hi77ZXD2BSWDD9wQe8vAhejXyS7vBM4"
+	owner, _ := crypto.GenerateHD()
+	ownerWallet, _ := owner.ToWallet()
+	stranger, _ := crypto.GenerateHD()
+	strangerWallet, _ := stranger.ToWallet()
+
+	tests := []struct {
+		name    string
+		build   func(l *ledger) *crypto.Tx
+		wantErr bool
+	}{
+		{
+			name: "Correct",
+			build: func(l *ledger) *crypto.Tx {
+				unspent, _ := l.fund(ownerWallet.Base58Address, 10000000)
+				tx, _ := crypto.NewTransaction([]crypto.TransactionInputOutpoint{unspent}, 1000, *owner,
+					ownerWallet.Base58Address, to, 1000000, crypto.NodeID{})
+				return tx
+			},
+			wantErr: false,
+		},
+		{
+			name: "double spend",
+			build: func(l *ledger) *crypto.Tx {
+				unspent, _ := l.fund(ownerWallet.Base58Address, 10000000)
+				first, _ := crypto.NewTransaction([]crypto.TransactionInputOutpoint{unspent}, 1000, *owner,
+					ownerWallet.Base58Address, to, 1000000, crypto.NodeID{})
+				if err := l.publish(first); err != nil {
+					t.Fatal(err)
+				}
+				tx, _ := crypto.NewTransaction([]crypto.TransactionInputOutpoint{unspent}, 2000, *owner,
+					ownerWallet.Base58Address, to, 1000000, crypto.NodeID{})
+				return tx
+			},
+			wantErr: true,
+		},
+		{
+			name: "foreign output",
+			build: func(l *ledger) *crypto.Tx {
+				unspent, _ := l.fund(ownerWallet.Base58Address, 10000000)
+				tx, _ := crypto.NewTransaction([]crypto.TransactionInputOutpoint{unspent}, 1000, *stranger,
+					strangerWallet.Base58Address, to, 1000000, crypto.NodeID{})
+				return tx
+			},
+			wantErr: true,
+		},
+		{
+			name: "wrong input value",
+			build: func(l *ledger) *crypto.Tx {
+				unspent, _ := l.fund(ownerWallet.Base58Address, 10000000)
+				unspent.Value = 20000000
+				tx, _ := crypto.NewTransaction([]crypto.TransactionInputOutpoint{unspent}, 1000, *owner,
+					ownerWallet.Base58Address, to, 1000000, crypto.NodeID{})
+				return tx
+			},
+			wantErr: true,
+		},
+		{
+			name: "broken signature",
+			build: func(l *ledger) *crypto.Tx {
+				unspent, _ := l.fund(ownerWallet.Base58Address, 10000000)
+				tx, _ := crypto.NewTransaction([]crypto.TransactionInputOutpoint{unspent}, 1000, *owner,
+					ownerWallet.Base58Address, to, 1000000, crypto.NodeID{})
+				tx.Inputs[0].Script[0] ^= 0xff
+				tx.Hash = tx.GenerateHash()
+				return tx
+			},
+			wantErr: true,
+		},
+		{
+			name: "small commission",
+			build: func(l *ledger) *crypto.Tx {
+				unspent, _ := l.fund(ownerWallet.Base58Address, 10000000)
+				tx, _ := crypto.NewTransaction([]crypto.TransactionInputOutpoint{unspent}, 1000, *owner,
+					ownerWallet.Base58Address, to, 999999, crypto.NodeID{})
+				return tx
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown output",
+			build: func(l *ledger) *crypto.Tx {
+				unspent := crypto.TransactionInputOutpoint{Hash: crypto.Hash{1}, Index: 0, Value: 10000000}
+				tx, _ := crypto.NewTransaction([]crypto.TransactionInputOutpoint{unspent}, 1000, *owner,
+					ownerWallet.Base58Address, to, 1000000, crypto.NodeID{})
+				return tx
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, err := newLedger()
+			if err != nil {
+				t.Fatal(err)
+			}
+			tx := tt.build(l)
+			if err := l.publish(tx); (err != nil) != tt.wantErr {
+				t.Errorf("publish() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLedger_Mine(t *testing.T) {
+	owner, _ := crypto.GenerateHD()
+	ownerWallet, _ := owner.ToWallet()
+	l, err := newLedger()
+	if err != nil {
+		t.Fatal(err)
+	}
+	unspent, _ := l.fund(ownerWallet.Base58Address, 10000000)
+	tx, _ := crypto.NewTransaction([]crypto.TransactionInputOutpoint{unspent}, 1000, *owner,
+		ownerWallet.Base58Address, "VLa1hi77ZXD2BSWDD9wQe8vAhejXyS7vBM4", 1000000, crypto.NodeID{})
+	if err := l.publish(tx); err != nil {
+		t.Fatal(err)
+	}
+	if got := l.unspents(ownerWallet.Base58Address, false); len(got) != 0 {
+		t.Errorf("unspents() before mine got = %v, want empty", got)
+	}
+	l.mine()
+	got := l.unspents(ownerWallet.Base58Address, false)
+	if len(got) != 1 || got[0].Value != 10000000-1000-1000000 {
+		t.Errorf("unspents() after mine got = %v, want change output", got)
+	}
+	if confirmed := l.confirmations(l.txs[tx.Hash].block); confirmed != 1 {
+		t.Errorf("confirmations() got = %d, want 1", confirmed)
+	}
+}
+
+func TestLedger_Rollback(t *testing.T) {
+	owner, _ := crypto.GenerateHD()
+	ownerWallet, _ := owner.ToWallet()
+	l, err := newLedger()
+	if err != nil {
+		t.Fatal(err)
+	}
+	unspent, _ := l.fund(ownerWallet.Base58Address, 10000000)
+	tx, _ := crypto.NewTransaction([]crypto.TransactionInputOutpoint{unspent}, 1000, *owner,
+		ownerWallet.Base58Address, "VLa1hi77ZXD2BSWDD9wQe8vAhejXyS7vBM4", 1000000, crypto.NodeID{})
+	if err := l.publish(tx); err != nil {
+		t.Fatal(err)
+	}
+	orphaned := l.mine()
+	if err := l.rollback(1); err != nil {
+		t.Fatalf("rollback() error = %v", err)
+	}
+	if l.tip().height != 1 || l.txs[tx.Hash].block != nil {
+		t.Errorf("rollback() tip = %d, transaction block = %v", l.tip().height, l.txs[tx.Hash].block)
+	}
+	if err := l.validate(tx); err == nil {
+		t.Errorf("validate() of returned transaction must fail, it is in mempool")
+	}
+	mined := l.mine()
+	if mined.hash == orphaned.hash || len(mined.txs) != 1 {
+		t.Errorf("mine() after rollback got = %x with %d txs", mined.hash, len(mined.txs))
+	}
+	if err := l.rollback(3); err == nil {
+		t.Errorf("rollback() of genesis block must fail")
+	}
+}
+
+func TestLedger_Evict(t *testing.T) {
+	owner, _ := crypto.GenerateHD()
+	ownerWallet, _ := owner.ToWallet()
+	l, err := newLedger()
+	if err != nil {
+		t.Fatal(err)
+	}
+	unspent, _ := l.fund(ownerWallet.Base58Address, 10000000)
+	tx, _ := crypto.NewTransaction([]crypto.TransactionInputOutpoint{unspent}, 1000, *owner,
+		ownerWallet.Base58Address, "VLa1hi77ZXD2BSWDD9wQe8vAhejXyS7vBM4", 1000000, crypto.NodeID{})
+	if err := l.publish(tx); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.evict(tx.Hash); err != nil {
+		t.Fatalf("evict() error = %v", err)
+	}
+	if got := l.unspents(ownerWallet.Base58Address, false); len(got) != 1 || got[0] != unspent {
+		t.Errorf("unspents() after evict got = %v, want %v", got, unspent)
+	}
+	if got := l.addrTxs[ownerWallet.Base58Address]; len(got) != 1 {
+		t.Errorf("address transactions after evict got = %d, want 1", len(got))
+	}
+	if err := l.evict(unspent.Hash); err == nil {
+		t.Errorf("evict() of mined transaction must fail")
+	}
+}
@@ -0,0 +1,445 @@
+// Package velastest implements in-memory Velas node for hermetic tests of httpClient and code built on top of it.
+//
+// Server serves the same REST API as the node, state is kept in a simple UTXO ledger which verifies signatures,
+// ownership and amounts of published transactions. Published transactions wait in mempool until Mine is called.
+package velastest
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"github.com/go-errors/errors"
+	"github.com/velas/GoVelas/crypto"
+	"github.com/velas/GoVelas/httpClient/response"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+)
+
+// Server is fake node, started on local address
+type Server struct {
+	URL    string // base address for httpClient.NewClient
+	srv    *httptest.Server
+	ledger *ledger
+}
+
+// NewServer start fake node with genesis block, server must be closed after using
+func NewServer() (*Server, error) {
+	l, err := newLedger()
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{
+		ledger: l,
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/info", s.handleInfo)
+	mux.HandleFunc("/api/v1/wallet/balance/", s.handleBalance)
+	mux.HandleFunc("/api/v1/wallet/unspent/", s.handleUnspent)
+	mux.HandleFunc("/api/v1/wallet/unspent_for_staking/", s.handleUnspentForStaking)
+	mux.HandleFunc("/api/v1/wallet/txs/", s.handleWalletTxs)
+	mux.HandleFunc("/api/v1/txs", s.handleTxs)
+	mux.HandleFunc("/api/v1/txs/height/", s.handleTxsByHeight)
+	mux.HandleFunc("/api/v1/txs/validate", s.handleValidate)
+	mux.HandleFunc("/api/v1/txs/publish", s.handlePublish)
+	mux.HandleFunc("/api/v1/blocks/", s.handleBlock)
+	s.srv = httptest.NewServer(mux)
+	s.URL = s.srv.URL
+	return s, nil
+}
+
+// Close shut down server
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// Fund mine block with transaction, which send amount to address, and return outpoint of created output
+func (s *Server) Fund(address string, amount uint64) (crypto.TransactionInputOutpoint, error) {
+	return s.ledger.fund(address, amount)
+}
+
+// Mine include all pending transactions to new block and return hash of block
+func (s *Server) Mine() crypto.Hash {
+	s.ledger.mu.Lock()
+	defer s.ledger.mu.Unlock()
+	return s.ledger.mine().hash
+}
+
+// Rollback remove depth last blocks from chain, their transactions return to mempool. Next Mine call create a block,
+// which competes with removed ones, so clients observe reorganization
+func (s *Server) Rollback(depth int) error {
+	s.ledger.mu.Lock()
+	defer s.ledger.mu.Unlock()
+	return s.ledger.rollback(depth)
+}
+
+// Evict remove pending transaction by hash from mempool, as node does with expired transactions
+func (s *Server) Evict(hash crypto.Hash) error {
+	s.ledger.mu.Lock()
+	defer s.ledger.mu.Unlock()
+	return s.ledger.evict(hash)
+}
+
+// SetFeeRates change rates of required commission, crypto.DefaultFeeRates are used by default
+func (s *Server) SetFeeRates(rates crypto.FeeRates) {
+	s.ledger.mu.Lock()
+	defer s.ledger.mu.Unlock()
+	s.ledger.rates = rates
+}
+
+// SetAutoMine enable mining of new block on every published transaction
+func (s *Server) SetAutoMine(autoMine bool) {
+	s.ledger.mu.Lock()
+	defer s.ledger.mu.Unlock()
+	s.ledger.autoMine = autoMine
+}
+
+// Height return height of last block, genesis block has height 0
+func (s *Server) Height() int {
+	s.ledger.mu.Lock()
+	defer s.ledger.mu.Unlock()
+	return s.ledger.tip().height
+}
+
+// ProducerKey return public key, which signs blocks
+func (s *Server) ProducerKey() []byte {
+	key, _ := hex.DecodeString(s.ledger.producer.PublicKey())
+	return key
+}
+
+// TipHash return hash of last block
+func (s *Server) TipHash() crypto.Hash {
+	s.ledger.mu.Lock()
+	defer s.ledger.mu.Unlock()
+	return s.ledger.tip().hash
+}
+
+// Header in node response format
+type headerJSON struct {
+	Type        uint32      `json:"type"`
+	Hash        crypto.Hash `json:"hash"`
+	Height      uint32      `json:"height"`
+	Size        uint64      `json:"size"`
+	Version     uint32      `json:"version"`
+	PrevBlock   crypto.Hash `json:"prev_block"`
+	MerkleRoot  crypto.Hash `json:"merkle_root"`
+	Timestamp   uint32      `json:"timestamp"`
+	Bits        uint32      `json:"bits"`
+	Nonce       uint32      `json:"nonce"`
+	Seed        string      `json:"seed"`
+	TxnCount    uint32      `json:"txn_count"`
+	AdviceCount uint32      `json:"advice_count"`
+	Script      string      `json:"script"`
+}
+
+// Block in node response format
+type blockJSON struct {
+	Header       headerJSON   `json:"header"`
+	Transactions []*crypto.Tx `json:"txns"`
+	Advices      []adviceJSON `json:"advices"`
+}
+
+type adviceJSON struct {
+	PublicKey string `json:"public_key"`
+}
+
+// writeJSON write object with status code
+func writeJSON(w http.ResponseWriter, status int, obj interface{}) {
+	body, err := json.Marshal(obj)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
+
+// writeError write error in node format
+func writeError(w http.ResponseWriter, status int, err error) {
+	body, _ := json.Marshal(map[string]string{
+		"status": http.StatusText(status),
+		"error":  err.Error(),
+	})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
+
+// checkMethod write error if method of request is not expected
+func checkMethod(w http.ResponseWriter, r *http.Request, method string) bool {
+	if r.Method != method {
+		writeError(w, http.StatusMethodNotAllowed, errors.Errorf("method %s not allowed", r.Method))
+		return false
+	}
+	return true
+}
+
+func (s *Server) handleInfo(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodGet) {
+		return
+	}
+	s.ledger.mu.Lock()
+	defer s.ledger.mu.Unlock()
+
+	tip := s.ledger.tip()
+	writeJSON(w, http.StatusOK, response.Node{
+		P2PInfo: &response.NodeInfo{
+			ID:   "velastest",
+			Name: "velastest",
+			Addr: s.URL,
+		},
+		P2PPeers: []*response.NodeInfo{},
+		Blockchain: &response.Blockchain{
+			Height:      tip.height,
+			CurrentHash: tip.hash,
+		},
+		IsSync: true,
+		Progress: &response.Progress{
+			CurrentBlock: uint32(tip.height),
+			HighestBlock: uint32(tip.height),
+		},
+	})
+}
+
+func (s *Server) handleBalance(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodGet) {
+		return
+	}
+	s.ledger.mu.Lock()
+	defer s.ledger.mu.Unlock()
+
+	address := strings.TrimPrefix(r.URL.Path, "/api/v1/wallet/balance/")
+	amount := uint64(0)
+	for _, unspent := range s.ledger.unspents(address, false) {
+		amount += unspent.Value
+	}
+	writeJSON(w, http.StatusOK, map[string]uint64{"amount": amount})
+}
+
+func (s *Server) handleUnspent(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodGet) {
+		return
+	}
+	s.ledger.mu.Lock()
+	defer s.ledger.mu.Unlock()
+
+	address := strings.TrimPrefix(r.URL.Path, "/api/v1/wallet/unspent/")
+	writeJSON(w, http.StatusOK, s.ledger.unspents(address, false))
+}
+
+func (s *Server) handleUnspentForStaking(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodGet) {
+		return
+	}
+	s.ledger.mu.Lock()
+	defer s.ledger.mu.Unlock()
+
+	address := strings.TrimPrefix(r.URL.Path, "/api/v1/wallet/unspent_for_staking/")
+	writeJSON(w, http.StatusOK, s.ledger.unspents(address, true))
+}
+
+func (s *Server) handleWalletTxs(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodGet) {
+		return
+	}
+	s.ledger.mu.Lock()
+	defer s.ledger.mu.Unlock()
+
+	address := strings.TrimPrefix(r.URL.Path, "/api/v1/wallet/txs/")
+	hashes := make([]crypto.Hash, 0)
+	hashes = append(hashes, s.ledger.addrTxs[address]...)
+	writeJSON(w, http.StatusOK, hashes)
+}
+
+func (s *Server) handleTxsByHeight(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodGet) {
+		return
+	}
+	height, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/api/v1/txs/height/"))
+	if err != nil || height < 0 {
+		writeError(w, http.StatusBadRequest, errors.Errorf("invalid height"))
+		return
+	}
+	s.ledger.mu.Lock()
+	defer s.ledger.mu.Unlock()
+
+	var hashes []crypto.Hash
+	for _, blk := range s.ledger.blocks {
+		if blk.height < height {
+			continue
+		}
+		for _, tx := range blk.txs {
+			hashes = append(hashes, tx.Hash)
+		}
+	}
+	writeJSON(w, http.StatusOK, hashes)
+}
+
+func (s *Server) handleTxs(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodPost) {
+		return
+	}
+	req := struct {
+		Hashes []string `json:"hashes"`
+	}{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	s.ledger.mu.Lock()
+	defer s.ledger.mu.Unlock()
+
+	result := make([]json.RawMessage, 0, len(req.Hashes))
+	for _, hashString := range req.Hashes {
+		hash, err := crypto.ParseHash(hashString)
+		if err != nil {
+			continue
+		}
+		entry, ok := s.ledger.txs[hash]
+		if !ok {
+			continue
+		}
+		body, err := s.txResponseJSON(entry)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		result = append(result, body)
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// txResponseJSON marshal transaction with block information
+func (s *Server) txResponseJSON(entry *txEntry) (json.RawMessage, error) {
+	txBody, err := json.Marshal(entry.tx)
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(txBody, &fields); err != nil {
+		return nil, err
+	}
+	blockHash := ""
+	timestamp := uint32(0)
+	if entry.block != nil {
+		blockHash = entry.block.hash.String()
+		timestamp = entry.block.timestamp
+	}
+	extra := map[string]interface{}{
+		"size":                len(txBody),
+		"block":               blockHash,
+		"confirmed":           s.ledger.confirmations(entry.block),
+		"confirmed_timestamp": timestamp,
+	}
+	for key, value := range extra {
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		fields[key] = raw
+	}
+	return json.Marshal(fields)
+}
+
+// readTx decode transaction from request body
+func readTx(r *http.Request) (*crypto.Tx, error) {
+	tx := &crypto.Tx{}
+	if err := json.NewDecoder(r.Body).Decode(tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+func (s *Server) handleValidate(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodPost) {
+		return
+	}
+	tx, err := readTx(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	s.ledger.mu.Lock()
+	err = s.ledger.validate(tx)
+	s.ledger.mu.Unlock()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"result": tx.Hash.String()})
+}
+
+func (s *Server) handlePublish(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodPost) {
+		return
+	}
+	tx, err := readTx(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := s.ledger.publish(tx); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"result": tx.Hash.String()})
+}
+
+func (s *Server) handleBlock(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodGet) {
+		return
+	}
+	s.ledger.mu.Lock()
+	defer s.ledger.mu.Unlock()
+
+	blk, status, err := s.findBlock(strings.TrimPrefix(r.URL.Path, "/api/v1/blocks/"))
+	if err != nil {
+		writeError(w, status, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.blockResponse(blk))
+}
+
+// findBlock find block by hash in hex, orphaned blocks are found too
+func (s *Server) findBlock(ref string) (*block, int, error) {
+	hash, err := crypto.ParseHash(ref)
+	if err != nil {
+		return nil, http.StatusBadRequest, errors.Errorf("invalid block hash")
+	}
+	blk, ok := s.ledger.byHash[hash]
+	if !ok {
+		return nil, http.StatusNotFound, errors.Errorf("block not found")
+	}
+	return blk, http.StatusOK, nil
+}
+
+// blockResponse convert block to node response format
+func (s *Server) blockResponse(blk *block) blockJSON {
+	txs := blk.txs
+	if txs == nil {
+		txs = []*crypto.Tx{}
+	}
+	size := uint64(0)
+	for _, tx := range txs {
+		body, _ := json.Marshal(tx)
+		size += uint64(len(body))
+	}
+	return blockJSON{
+		Header: headerJSON{
+			Hash:        blk.hash,
+			Height:      uint32(blk.height),
+			Size:        size,
+			Version:     1,
+			PrevBlock:   blk.prevBlock,
+			MerkleRoot:  blk.merkleRoot,
+			Timestamp:   blk.timestamp,
+			Nonce:       blk.nonce,
+			TxnCount:    uint32(len(txs)),
+			AdviceCount: 1,
+			Script:      hex.EncodeToString(blk.signature),
+		},
+		Transactions: txs,
+		Advices:      []adviceJSON{{PublicKey: s.ledger.producer.PublicKey()}},
+	}
+}
@@ -14,7 +14,7 @@ type Wallet struct {
 
 // Balance response
 type Balance struct {
-	Amount uint64 `json:"amount"`
+	Amount crypto.Amount `json:"amount"`
 }
 
 // Create wallet node client
@@ -25,7 +25,7 @@ func newWalletClient(bk *baseClient) *Wallet {
 }
 
 // Get balance of wallet by base58 address
-func (w *Wallet) GetBalance(address string) (uint64, error) {
+func (w *Wallet) GetBalance(address string) (crypto.Amount, error) {
 	resp, err := resty.
 		R().
 		Get(w.bk.baseAddress + "/api/v1/wallet/balance/" + address)
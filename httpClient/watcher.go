@@ -0,0 +1,249 @@
+package httpClient
+
+import (
+	"bytes"
+	"context"
+	"github.com/velas/GoVelas/crypto"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Default interval between checks of watched addresses
+const DefaultWatcherPollInterval = 5 * time.Second
+
+// State of transaction, observed by watcher
+type WatchedTx struct {
+	Hash      crypto.Hash // transaction hash
+	Addresses []string    // watched addresses, which transaction belongs to
+	Block     crypto.Hash // block hash, empty while transaction is not in a block
+	Confirmed uint32      // count of confirmations
+	Tx        *TxResponse // last response of node, nil for dropped transactions
+}
+
+// Options of wallet transaction watcher, all callbacks are optional and called from the goroutine of Poll
+type WatcherOptions struct {
+	Confirmations   uint32        // threshold for OnConfirmed, transaction must be in a block, it is not tracked after it
+	PollInterval    time.Duration // DefaultWatcherPollInterval if zero
+	IgnoreExisting  bool          // transactions, which exist on subscription, are not reported
+	OnNew           func(tx WatchedTx)
+	OnConfirmations func(tx WatchedTx) // count of confirmations is changed
+	OnConfirmed     func(tx WatchedTx) // threshold is reached, called once for transaction
+	OnDropped       func(tx WatchedTx) // tracked transaction disappeared from node
+}
+
+// Watcher of wallet transactions, it tracks confirmations of transactions of subscribed addresses
+type Watcher struct {
+	tx        *Tx
+	opts      WatcherOptions
+	mu        sync.Mutex
+	addresses map[string]bool // subscribed addresses, false until the first poll of address
+	tracked   map[crypto.Hash]*WatchedTx
+	finished  map[crypto.Hash]bool // confirmed, dropped or ignored transactions
+}
+
+// Create watcher of wallet transactions, addresses can be added later with Subscribe
+func (cl *Client) NewWatcher(opts WatcherOptions, addresses ...string) *Watcher {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultWatcherPollInterval
+	}
+	w := &Watcher{
+		tx:        cl.Tx,
+		opts:      opts,
+		addresses: make(map[string]bool),
+		tracked:   make(map[crypto.Hash]*WatchedTx),
+		finished:  make(map[crypto.Hash]bool),
+	}
+	for _, address := range addresses {
+		w.Subscribe(address)
+	}
+	return w
+}
+
+// Subscribe add address to watch list
+func (w *Watcher) Subscribe(address string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.addresses[address]; !ok {
+		w.addresses[address] = false
+	}
+}
+
+// Unsubscribe remove address from watch list, transactions of address are not tracked after next poll
+func (w *Watcher) Unsubscribe(address string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.addresses, address)
+}
+
+// Tracked return transactions, which are waiting for confirmations, sorted by hash
+func (w *Watcher) Tracked() []WatchedTx {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	result := make([]WatchedTx, 0, len(w.tracked))
+	for _, watched := range w.tracked {
+		result = append(result, *watched)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return bytes.Compare(result[i].Hash[:], result[j].Hash[:]) < 0
+	})
+	return result
+}
+
+// Run poll node until context is done or error occurs
+func (w *Watcher) Run(ctx context.Context) error {
+	for {
+		if err := w.Poll(); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(w.opts.PollInterval):
+		}
+	}
+}
+
+// Poll check watched addresses once and call callbacks
+func (w *Watcher) Poll() error {
+	w.mu.Lock()
+	addresses := make(map[string]bool, len(w.addresses))
+	for address, polled := range w.addresses {
+		addresses[address] = polled
+	}
+	w.mu.Unlock()
+
+	sorted := make([]string, 0, len(addresses))
+	for address := range addresses {
+		sorted = append(sorted, address)
+	}
+	sort.Strings(sorted)
+
+	// hashes of transactions by address
+	owners := make(map[crypto.Hash][]string)
+	for _, address := range sorted {
+		hashes, err := w.tx.GetHashListByAddress(address)
+		if err != nil {
+			return err
+		}
+		for _, hash := range hashes {
+			owners[hash] = append(owners[hash], address)
+		}
+	}
+
+	w.mu.Lock()
+	for hash, hashOwners := range owners {
+		if w.finished[hash] {
+			continue
+		}
+		if _, ok := w.tracked[hash]; ok {
+			w.tracked[hash].Addresses = hashOwners
+			continue
+		}
+		if w.opts.IgnoreExisting && allExisting(hashOwners, addresses) {
+			w.finished[hash] = true
+			continue
+		}
+		w.tracked[hash] = &WatchedTx{Hash: hash, Addresses: hashOwners}
+	}
+	for address := range addresses {
+		if _, ok := w.addresses[address]; ok {
+			w.addresses[address] = true
+		}
+	}
+	hashes := make([]crypto.Hash, 0, len(w.tracked))
+	for hash, watched := range w.tracked {
+		if !hasSubscribed(watched.Addresses, w.addresses) {
+			// all addresses of transaction are unsubscribed
+			delete(w.tracked, hash)
+			continue
+		}
+		hashes = append(hashes, hash)
+	}
+	w.mu.Unlock()
+
+	if len(hashes) == 0 {
+		return nil
+	}
+	sort.Slice(hashes, func(i, j int) bool {
+		return bytes.Compare(hashes[i][:], hashes[j][:]) < 0
+	})
+	result, err := w.tx.LookupHashList(hashes)
+	if err != nil {
+		return err
+	}
+
+	callbacks := make([]func(), 0)
+	w.mu.Lock()
+	for i := range result.Transactions {
+		txResponse := result.Transactions[i]
+		hash := txResponse.Hash
+		watched, ok := w.tracked[hash]
+		if !ok {
+			continue
+		}
+		isNew := watched.Tx == nil
+		changed := watched.Confirmed != txResponse.Confirmed || watched.Block != txResponse.Block
+		watched.Tx = &txResponse
+		watched.Block = txResponse.Block
+		watched.Confirmed = txResponse.Confirmed
+		state := *watched
+
+		if isNew {
+			callbacks = append(callbacks, bindCallback(w.opts.OnNew, state))
+		} else if changed {
+			callbacks = append(callbacks, bindCallback(w.opts.OnConfirmations, state))
+		}
+		if !txResponse.Block.IsEmpty() && txResponse.Confirmed >= w.opts.Confirmations {
+			delete(w.tracked, hash)
+			w.finished[hash] = true
+			callbacks = append(callbacks, bindCallback(w.opts.OnConfirmed, state))
+		}
+	}
+	for _, hash := range result.Missing {
+		watched, ok := w.tracked[hash]
+		if !ok {
+			continue
+		}
+		delete(w.tracked, hash)
+		w.finished[hash] = true
+		state := *watched
+		state.Tx = nil
+		callbacks = append(callbacks, bindCallback(w.opts.OnDropped, state))
+	}
+	w.mu.Unlock()
+
+	for _, callback := range callbacks {
+		callback()
+	}
+	return nil
+}
+
+// bindCallback bind state to optional callback
+func bindCallback(fn func(tx WatchedTx), state WatchedTx) func() {
+	return func() {
+		if fn != nil {
+			fn(state)
+		}
+	}
+}
+
+// allExisting check that all addresses are polled first time
+func allExisting(owners []string, polled map[string]bool) bool {
+	for _, address := range owners {
+		if polled[address] {
+			return false
+		}
+	}
+	return true
+}
+
+// hasSubscribed check that any address is subscribed
+func hasSubscribed(owners []string, subscribed map[string]bool) bool {
+	for _, address := range owners {
+		if _, ok := subscribed[address]; ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package httpClient
+
+import (
+	"fmt"
+	"github.com/velas/GoVelas/crypto"
+	"reflect"
+	"testing"
+)
+
+func TestWatcher_Poll(t *testing.T) {
+	const to = "VLa1hi77ZXD2BSWDD9wQe8vAhejXyS7vBM4"
+	srv := newTestServer(t)
+	defer srv.Close()
+	client := NewClient(srv.URL)
+
+	events := make([]string, 0)
+	record := func(kind string) func(tx WatchedTx) {
+		return func(tx WatchedTx) {
+			events = append(events, fmt.Sprintf("%s:%s:%d", kind, tx.Hash.String()[:8], tx.Confirmed))
+		}
+	}
+	w := client.NewWatcher(WatcherOptions{
+		Confirmations:   2,
+		OnNew:           record("new"),
+		OnConfirmations: record("confirmations"),
+		OnConfirmed:     record("confirmed"),
+		OnDropped:       record("dropped"),
+	}, to)
+
+	poll := func() {
+		if err := w.Poll(); err != nil {
+			t.Fatalf("Poll() error = %v", err)
+		}
+	}
+
+	poll()
+	if len(events) != 0 {
+		t.Fatalf("Poll() without transactions got = %v", events)
+	}
+
+	first := sendTestTx(t, client, Pk, to, 1000)
+	poll()
+	srv.Mine()
+	poll()
+	srv.Mine()
+	poll()
+	poll()
+
+	second := sendTestTx(t, client, Pk2, to, 2000)
+	poll()
+	if err := srv.Evict(second); err != nil {
+		t.Fatal(err)
+	}
+	poll()
+
+	want := []string{
+		"new:" + first.String()[:8] + ":0",
+		"confirmations:" + first.String()[:8] + ":1",
+		"confirmations:" + first.String()[:8] + ":2",
+		"confirmed:" + first.String()[:8] + ":2",
+		"new:" + second.String()[:8] + ":0",
+		"dropped:" + second.String()[:8] + ":0",
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("Poll() events got = %v, want %v", events, want)
+	}
+	if tracked := w.Tracked(); len(tracked) != 0 {
+		t.Errorf("Tracked() got = %v, want empty", tracked)
+	}
+}
+
+func TestWatcher_IgnoreExisting(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
+	client := NewClient(srv.URL)
+	hd, _ := crypto.HDFromPrivateKeyHex(Pk)
+	wallet, _ := hd.ToWallet()
+
+	seen := make([]crypto.Hash, 0)
+	w := client.NewWatcher(WatcherOptions{
+		Confirmations:  1,
+		IgnoreExisting: true,
+		OnNew: func(tx WatchedTx) {
+			seen = append(seen, tx.Hash)
+		},
+	}, wallet.Base58Address)
+	if err := w.Poll(); err != nil {
+		t.Fatal(err)
+	}
+	hash := sendTestTx(t, client, Pk, "VLa1hi77ZXD2BSWDD9wQe8vAhejXyS7vBM4", 1000)
+	if err := w.Poll(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(seen, []crypto.Hash{hash}) {
+		t.Errorf("Poll() new got = %v, want only %s", seen, hash)
+	}
+	tracked := w.Tracked()
+	if len(tracked) != 1 || !reflect.DeepEqual(tracked[0].Addresses, []string{wallet.Base58Address}) {
+		t.Errorf("Tracked() got = %+v", tracked)
+	}
+}